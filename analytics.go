@@ -0,0 +1,325 @@
+package dhanhq
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// UnclassifiedSector is the sector of holdings missing from ReportOptions.Sectors
+const UnclassifiedSector = "Unclassified"
+
+// marketDataBatchSize is the most securities DhanHQ accepts in one market quote request
+const marketDataBatchSize = 1000
+
+// HoldingValuation is a holding valued at its last traded price
+type HoldingValuation struct {
+	TradingSymbol    string  `json:"tradingSymbol"`
+	SecurityId       string  `json:"securityId"`
+	ISIN             string  `json:"isin"`
+	Sector           string  `json:"sector"`
+	Quantity         int32   `json:"quantity"`
+	AvgCostPrice     float64 `json:"avgCostPrice"`
+	LastPrice        float64 `json:"lastPrice"`
+	PreviousClose    float64 `json:"previousClose"`
+	InvestedValue    float64 `json:"investedValue"`
+	CurrentValue     float64 `json:"currentValue"`
+	Profit           float64 `json:"profit"`
+	ProfitPercent    float64 `json:"profitPercent"`
+	DayChange        float64 `json:"dayChange"`
+	DayChangePercent float64 `json:"dayChangePercent"`
+	Weight           float64 `json:"weight"`
+}
+
+// HoldingsGroup sums the holdings of a sector or an ISIN
+type HoldingsGroup struct {
+	Key           string  `json:"key"`
+	Count         int     `json:"count"`
+	InvestedValue float64 `json:"investedValue"`
+	CurrentValue  float64 `json:"currentValue"`
+	Profit        float64 `json:"profit"`
+	ProfitPercent float64 `json:"profitPercent"`
+	Weight        float64 `json:"weight"`
+}
+
+// Concentration measures how much of the portfolio sits in few holdings,
+// weights are fractions of the current value
+type Concentration struct {
+	Largest         string  `json:"largest"`
+	LargestWeight   float64 `json:"largestWeight"`
+	Top5Weight      float64 `json:"top5Weight"`
+	HerfindahlIndex float64 `json:"herfindahlIndex"`
+}
+
+// HoldingsReport values the holdings and groups them by sector and ISIN
+type HoldingsReport struct {
+	GeneratedAt      time.Time          `json:"generatedAt"`
+	Holdings         []HoldingValuation `json:"holdings"`
+	BySector         []HoldingsGroup    `json:"bySector"`
+	ByISIN           []HoldingsGroup    `json:"byIsin"`
+	Concentration    Concentration      `json:"concentration"`
+	InvestedValue    float64            `json:"investedValue"`
+	CurrentValue     float64            `json:"currentValue"`
+	Profit           float64            `json:"profit"`
+	ProfitPercent    float64            `json:"profitPercent"`
+	DayChange        float64            `json:"dayChange"`
+	DayChangePercent float64            `json:"dayChangePercent"`
+}
+
+// ReportOptions configures HoldingsReport
+type ReportOptions struct {
+	// Sectors maps an ISIN or a trading symbol to its sector
+	Sectors map[string]string
+
+	// RefreshPrices replaces the LastTradedPrice of the holdings with GetLTP
+	RefreshPrices bool
+
+	// DayChange fetches the previous close with GetOHLC to compute the day change
+	DayChange bool
+
+	// ExchangeSegment is the segment the prices of holdings listed on both
+	// exchanges are fetched from, ExchangeSegmentEquityNSE by default.
+	// Holdings only listed on BSE are priced from ExchangeSegmentEquityBSE.
+	ExchangeSegment string
+}
+
+// HoldingsReport fetches the holdings and builds their report
+func (c *Client) HoldingsReport(ctx context.Context, opts ReportOptions) (HoldingsReport, error) {
+	holdings, err := c.GetHoldingsContext(ctx)
+	if err != nil {
+		return HoldingsReport{}, err
+	}
+	defaultSegment := opts.ExchangeSegment
+	if defaultSegment == "" {
+		defaultSegment = ExchangeSegmentEquityNSE
+	}
+
+	// Batches of up to marketDataBatchSize securities, across segments
+	var batches []MarketDataInput
+	var size int
+	for _, holding := range holdings.Holdings {
+		id, err := strconv.Atoi(holding.SecurityId)
+		if err != nil {
+			continue
+		}
+		segment := holdingSegment(holding, defaultSegment)
+		if len(batches) == 0 || size == marketDataBatchSize {
+			batches = append(batches, MarketDataInput{})
+			size = 0
+		}
+		batch := batches[len(batches)-1]
+		batch[segment] = append(batch[segment], id)
+		size++
+	}
+	if !opts.RefreshPrices && !opts.DayChange {
+		batches = nil
+	}
+
+	// DhanHQ allows one market quote request per second
+	limiter := NewRateLimiter(1/DefaultLTPPollInterval.Seconds(), 1)
+	// Keyed by segment and security id, a security id is only unique within its segment
+	lastPrices := make(map[string]float64)
+	previousCloses := make(map[string]float64)
+	for _, batch := range batches {
+		if opts.RefreshPrices {
+			if err := limiter.Wait(ctx); err != nil {
+				return HoldingsReport{}, err
+			}
+			ltp, err := c.GetLTPContext(ctx, batch)
+			if err != nil {
+				return HoldingsReport{}, err
+			}
+			for segment, quotes := range ltp.Data {
+				for securityId, quote := range quotes {
+					lastPrices[instrumentKey(segment, securityId)] = quote.LastPrice
+				}
+			}
+		}
+		if opts.DayChange {
+			if err := limiter.Wait(ctx); err != nil {
+				return HoldingsReport{}, err
+			}
+			ohlc, err := c.GetOHLCContext(ctx, batch)
+			if err != nil {
+				return HoldingsReport{}, err
+			}
+			for segment, quotes := range ohlc.Data {
+				for securityId, quote := range quotes {
+					previousCloses[instrumentKey(segment, securityId)] = quote.OHLC.Close
+				}
+			}
+		}
+	}
+
+	for i, holding := range holdings.Holdings {
+		key := instrumentKey(holdingSegment(holding, defaultSegment), holding.SecurityId)
+		if price, ok := lastPrices[key]; ok && price > 0 {
+			holdings.Holdings[i].LastTradedPrice = price
+		}
+	}
+	return newHoldingsReport(holdings, previousCloses, opts.Sectors, defaultSegment), nil
+}
+
+// holdingSegment returns the segment a holding is priced from, holdings
+// listed on both exchanges are priced from defaultSegment
+func holdingSegment(holding Holding, defaultSegment string) string {
+	if holding.Exchange == "BSE" {
+		return ExchangeSegmentEquityBSE
+	}
+	return defaultSegment
+}
+
+// NewHoldingsReport values holdings at their LastTradedPrice. previousCloses
+// gives the day change and may be nil, it is keyed by exchange segment and
+// security id such as "NSE_EQ:1333", with holdings listed on both exchanges
+// looked up in ExchangeSegmentEquityNSE. sectors maps an ISIN or a trading
+// symbol to its sector and may be nil.
+func NewHoldingsReport(holdings Holdings, previousCloses map[string]float64, sectors map[string]string) HoldingsReport {
+	return newHoldingsReport(holdings, previousCloses, sectors, ExchangeSegmentEquityNSE)
+}
+
+func newHoldingsReport(holdings Holdings, previousCloses map[string]float64, sectors map[string]string, defaultSegment string) HoldingsReport {
+	report := HoldingsReport{GeneratedAt: time.Now()}
+	var previousValue float64
+	for _, holding := range holdings.Holdings {
+		v := HoldingValuation{
+			TradingSymbol: holding.TradingSymbol,
+			SecurityId:    holding.SecurityId,
+			ISIN:          holding.ISIN,
+			Sector:        sectorOf(holding, sectors),
+			Quantity:      holding.TotalQty,
+			AvgCostPrice:  holding.AvgCostPrice,
+			LastPrice:     holding.LastTradedPrice,
+			PreviousClose: previousCloses[instrumentKey(holdingSegment(holding, defaultSegment), holding.SecurityId)],
+		}
+		v.InvestedValue = float64(v.Quantity) * v.AvgCostPrice
+		v.CurrentValue = float64(v.Quantity) * v.LastPrice
+		v.Profit = v.CurrentValue - v.InvestedValue
+		v.ProfitPercent = percent(v.Profit, v.InvestedValue)
+		if v.PreviousClose > 0 {
+			v.DayChange = float64(v.Quantity) * (v.LastPrice - v.PreviousClose)
+			v.DayChangePercent = percent(v.LastPrice-v.PreviousClose, v.PreviousClose)
+			previousValue += float64(v.Quantity) * v.PreviousClose
+		}
+
+		report.Holdings = append(report.Holdings, v)
+		report.InvestedValue += v.InvestedValue
+		report.CurrentValue += v.CurrentValue
+		report.DayChange += v.DayChange
+	}
+	report.Profit = report.CurrentValue - report.InvestedValue
+	report.ProfitPercent = percent(report.Profit, report.InvestedValue)
+	report.DayChangePercent = percent(report.DayChange, previousValue)
+
+	for i := range report.Holdings {
+		report.Holdings[i].Weight = fraction(report.Holdings[i].CurrentValue, report.CurrentValue)
+	}
+	sort.SliceStable(report.Holdings, func(i, j int) bool {
+		return report.Holdings[i].CurrentValue > report.Holdings[j].CurrentValue
+	})
+
+	report.BySector = groupHoldings(report, func(v HoldingValuation) string { return v.Sector })
+	report.ByISIN = groupHoldings(report, func(v HoldingValuation) string { return v.ISIN })
+	report.Concentration = concentration(report.ByISIN)
+	return report
+}
+
+func sectorOf(holding Holding, sectors map[string]string) string {
+	if sector, ok := sectors[holding.ISIN]; ok {
+		return sector
+	}
+	if sector, ok := sectors[holding.TradingSymbol]; ok {
+		return sector
+	}
+	return UnclassifiedSector
+}
+
+// groupHoldings sums the holdings by key, largest group first
+func groupHoldings(report HoldingsReport, key func(HoldingValuation) string) []HoldingsGroup {
+	index := make(map[string]int)
+	var groups []HoldingsGroup
+	for _, v := range report.Holdings {
+		k := key(v)
+		i, ok := index[k]
+		if !ok {
+			i = len(groups)
+			index[k] = i
+			groups = append(groups, HoldingsGroup{Key: k})
+		}
+		groups[i].Count++
+		groups[i].InvestedValue += v.InvestedValue
+		groups[i].CurrentValue += v.CurrentValue
+	}
+	for i := range groups {
+		groups[i].Profit = groups[i].CurrentValue - groups[i].InvestedValue
+		groups[i].ProfitPercent = percent(groups[i].Profit, groups[i].InvestedValue)
+		groups[i].Weight = fraction(groups[i].CurrentValue, report.CurrentValue)
+	}
+	sort.SliceStable(groups, func(i, j int) bool { return groups[i].CurrentValue > groups[j].CurrentValue })
+	return groups
+}
+
+// concentration measures groups sorted largest first
+func concentration(groups []HoldingsGroup) Concentration {
+	var c Concentration
+	for i, group := range groups {
+		if i == 0 {
+			c.Largest = group.Key
+			c.LargestWeight = group.Weight
+		}
+		if i < 5 {
+			c.Top5Weight += group.Weight
+		}
+		c.HerfindahlIndex += group.Weight * group.Weight
+	}
+	return c
+}
+
+func fraction(part, whole float64) float64 {
+	if whole == 0 {
+		return 0
+	}
+	return part / whole
+}
+
+func percent(part, whole float64) float64 {
+	return 100 * fraction(part, whole)
+}
+
+// WriteJSON writes the report as indented JSON
+func (r HoldingsReport) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// WriteCSV writes one row per holding
+func (r HoldingsReport) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	header := []string{
+		"tradingSymbol", "securityId", "isin", "sector", "quantity", "avgCostPrice", "lastPrice",
+		"previousClose", "investedValue", "currentValue", "profit", "profitPercent",
+		"dayChange", "dayChangePercent", "weight",
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	number := func(f float64) string { return strconv.FormatFloat(f, 'f', -1, 64) }
+	for _, v := range r.Holdings {
+		row := []string{
+			v.TradingSymbol, v.SecurityId, v.ISIN, v.Sector, strconv.Itoa(int(v.Quantity)),
+			number(v.AvgCostPrice), number(v.LastPrice), number(v.PreviousClose),
+			number(v.InvestedValue), number(v.CurrentValue), number(v.Profit), number(v.ProfitPercent),
+			number(v.DayChange), number(v.DayChangePercent), number(v.Weight),
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
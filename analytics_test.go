@@ -0,0 +1,91 @@
+package dhanhq
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHoldingsReportPricesBySegment(t *testing.T) {
+	// The same security id refers to different securities on NSE and BSE
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case URIHoldings:
+			w.Write([]byte(`[
+				{"exchange":"ALL","tradingSymbol":"HDFCBANK","securityId":"1333","isin":"INE040A01034","totalQty":10,"avgCostPrice":1500,"lastTradedPrice":1550},
+				{"exchange":"BSE","tradingSymbol":"SMALLCO","securityId":"1333","isin":"INE000000001","totalQty":100,"avgCostPrice":30,"lastTradedPrice":45}
+			]`))
+		case URIMarketfeedLTP:
+			w.Write([]byte(`{"data":{"NSE_EQ":{"1333":{"last_price":1700}},"BSE_EQ":{"1333":{"last_price":50}}},"status":"success"}`))
+		case URIMarketfeedOHLC:
+			w.Write([]byte(`{"data":{"NSE_EQ":{"1333":{"last_price":1700,"ohlc":{"close":1600}}},"BSE_EQ":{"1333":{"last_price":50,"ohlc":{"close":40}}}},"status":"success"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+	client := NewClient(WithBaseURI(srv.URL), WithCredentials("client", "token"))
+
+	report, err := client.HoldingsReport(context.Background(), ReportOptions{RefreshPrices: true, DayChange: true})
+	if err != nil {
+		t.Fatalf("HoldingsReport: %v", err)
+	}
+	want := map[string][2]float64{"HDFCBANK": {1700, 1600}, "SMALLCO": {50, 40}}
+	for _, v := range report.Holdings {
+		if prices := want[v.TradingSymbol]; v.LastPrice != prices[0] || v.PreviousClose != prices[1] {
+			t.Errorf("%s is priced at %g with a previous close of %g, want %g and %g",
+				v.TradingSymbol, v.LastPrice, v.PreviousClose, prices[0], prices[1])
+		}
+	}
+	if report.DayChange != 10*100+100*10 {
+		t.Errorf("day change is %g, want 2000", report.DayChange)
+	}
+}
+
+func TestHoldingsReportReturnsPriceErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == URIHoldings {
+			w.Write([]byte(`[{"exchange":"ALL","tradingSymbol":"HDFCBANK","securityId":"1333","totalQty":10,"avgCostPrice":1500,"lastTradedPrice":1550}]`))
+			return
+		}
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"errorType":"Rate_Limit","errorCode":"DH-904","errorMessage":"Too many requests"}`))
+	}))
+	defer srv.Close()
+	client := NewClient(WithBaseURI(srv.URL), WithCredentials("client", "token"))
+
+	_, err := client.HoldingsReport(context.Background(), ReportOptions{RefreshPrices: true})
+	var errResp ErrorResponse
+	if !errors.As(err, &errResp) || errResp.ErrorCode != "DH-904" {
+		t.Errorf("got %v, want the DH-904 error instead of a report at stale prices", err)
+	}
+}
+
+func TestNewHoldingsReport(t *testing.T) {
+	holdings := Holdings{Holdings: []Holding{
+		{Exchange: "ALL", TradingSymbol: "HDFCBANK", SecurityId: "1333", ISIN: "INE040A01034", TotalQty: 10, AvgCostPrice: 1500, LastTradedPrice: 1650},
+		{Exchange: "BSE", TradingSymbol: "SMALLCO", SecurityId: "1333", ISIN: "INE000000001", TotalQty: 100, AvgCostPrice: 30, LastTradedPrice: 35},
+	}}
+	previousCloses := map[string]float64{"NSE_EQ:1333": 1600, "BSE_EQ:1333": 40}
+	report := NewHoldingsReport(holdings, previousCloses, map[string]string{"INE040A01034": "Banks"})
+
+	if report.InvestedValue != 18000 || report.CurrentValue != 20000 || report.Profit != 2000 {
+		t.Errorf("report values are %g invested, %g current, %g profit", report.InvestedValue, report.CurrentValue, report.Profit)
+	}
+	// 10 * (1650 - 1600) + 100 * (35 - 40)
+	if report.DayChange != 0 {
+		t.Errorf("day change is %g, want 0", report.DayChange)
+	}
+	if report.Holdings[0].TradingSymbol != "HDFCBANK" || math.Abs(report.Holdings[0].Weight-0.825) > 1e-9 {
+		t.Errorf("largest holding is %+v, want HDFCBANK at 82.5%%", report.Holdings[0])
+	}
+	if report.Holdings[0].Sector != "Banks" {
+		t.Errorf("HDFCBANK sector is %q", report.Holdings[0].Sector)
+	}
+	if report.Holdings[1].PreviousClose != 40 {
+		t.Errorf("SMALLCO previous close is %g, want the BSE close", report.Holdings[1].PreviousClose)
+	}
+}
@@ -5,7 +5,12 @@ License or smth idk
 package dhanhq
 
 import (
+	"encoding/json"
+	"fmt"
 	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // Information about the library and some useful constants
@@ -16,14 +21,34 @@ const (
 	authURI string = "https://auth.dhan.co"
 )
 
-// Client represents the interface for DhanHQ API client
+// Client represents the interface for DhanHQ API client.
+// A Client is safe for concurrent use by multiple goroutines, its setters
+// swap the whole configuration atomically so that requests already in
+// flight keep using the credentials they started with.
 type Client struct {
+	// mu serializes the setters, readers never take it
+	mu     sync.Mutex
+	config atomic.Pointer[clientConfig]
+
+	// tokens tracks the expiry of the access token
+	tokens TokenManager
+}
+
+// clientConfig is an immutable snapshot of a Client's configuration,
+// every request reads a single snapshot and setters replace it as a whole
+type clientConfig struct {
 	dhanClientId string
 	accessToken  string
 	baseURI      string
 	authURI      string
 	partnerId    string
 
+	// tokenExpiry is the zero time when the expiry is unknown
+	tokenExpiry time.Time
+
+	// tokenStore is optional and persists the credentials
+	tokenStore TokenStore
+
 	// HTTP client for making requests
 	httpClient HTTPClient
 }
@@ -42,6 +67,9 @@ const (
 	ExchangeSegmentFNONSE    = "NSE_FNO"
 	ExchangeSegmentFNOBSE    = "BSE_FNO"
 	ExchangeSegmentMCXCOMM   = "MCX_COMM"
+	ExchangeSegmentCurNSE    = "NSE_CURRENCY"
+	ExchangeSegmentCurBSE    = "BSE_CURRENCY"
+	ExchangeSegmentIndex     = "IDX_I"
 
 	ProductTypeIntraday = "INTRADAY"
 	ProductTypeCNC      = "CNC"
@@ -49,6 +77,35 @@ const (
 	ProductTypeMTF      = "MTF"
 	ProductTypeCO       = "CO"
 	ProductTypeBO       = "BO"
+
+	OrderTypeLimit          = "LIMIT"
+	OrderTypeMarket         = "MARKET"
+	OrderTypeStopLoss       = "STOP_LOSS"
+	OrderTypeStopLossMarket = "STOP_LOSS_MARKET"
+
+	ValidityDay = "DAY"
+	ValidityIOC = "IOC"
+
+	OrderStatusTransit    = "TRANSIT"
+	OrderStatusPending    = "PENDING"
+	OrderStatusRejected   = "REJECTED"
+	OrderStatusCancelled  = "CANCELLED"
+	OrderStatusPartTraded = "PART_TRADED"
+	OrderStatusTraded     = "TRADED"
+	OrderStatusExpired    = "EXPIRED"
+	OrderStatusTriggered  = "TRIGGERED"
+	OrderStatusClosed     = "CLOSED"
+	OrderStatusConfirm    = "CONFIRM"
+
+	OrderFlagSingle = "SINGLE"
+	OrderFlagOCO    = "OCO"
+
+	KillSwitchActivate   = "ACTIVATE"
+	KillSwitchDeactivate = "DEACTIVATE"
+
+	LegNameEntry    = "ENTRY_LEG"
+	LegNameTarget   = "TARGET_LEG"
+	LegNameStopLoss = "STOP_LOSS_LEG"
 )
 
 // API endpoints for DhanHQ
@@ -59,6 +116,12 @@ const (
 	URIPartnerConsentLogin    = "/partner/consent-login"
 	URIPartnerConsumeConsent  = "/partner/consume-consent"
 
+	// Individual (API key) endpoints for auth
+
+	URIAppGenerateConsent = "/app/generate-consent"
+	URIAppConsentLogin    = "/login/consentApp-login"
+	URIAppConsumeConsent  = "/app/consumeApp-consent"
+
 	// Data endpoints
 
 	URIMarketfeedLTP   = "/marketfeed/ltp"
@@ -83,8 +146,9 @@ const (
 
 	// Fund related endpoints
 
-	URIMarginCalculator = "/margincalculator"
-	URIFundLimit        = "/fundlimit"
+	URIMarginCalculator      = "/margincalculator"
+	URIMarginCalculatorMulti = "/margincalculator/multi"
+	URIFundLimit             = "/fundlimit"
 
 	// Profile endpoints
 
@@ -103,77 +167,236 @@ const (
 
 	URIGetTrades        = "/trades"
 	URIGetTradesByOrder = "/trades/%s"
+
+	// Statement endpoints
+
+	URILedger       = "/ledger"
+	URITradeHistory = "/trades/%s/%s/%s"
+
+	// Super order endpoints
+
+	URISuperOrders         = "/super/orders"
+	URIModifySuperOrder    = "/super/orders/%s"
+	URICancelSuperOrderLeg = "/super/orders/%s/%s"
+
+	// Forever order endpoints
+
+	URIForeverOrders      = "/forever/orders"
+	URIModifyForeverOrder = "/forever/orders/%s"
+	URICancelForeverOrder = "/forever/orders/%s"
+	URIGetForeverOrders   = "/forever/all"
+
+	// Trader's control endpoints
+
+	URIKillSwitch = "/killswitch"
+
+	// eDIS endpoints
+
+	URIEDISTPIN    = "/edis/tpin"
+	URIEDISForm    = "/edis/form"
+	URIEDISInquire = "/edis/inquire/%s"
 )
 
+// endpointTemplates lists every URI constant so that metrics and tracing
+// can report requests per endpoint, new endpoints must be added here
+var endpointTemplates = []string{
+	URIPartnerGenerateConsent,
+	URIPartnerConsentLogin,
+	URIPartnerConsumeConsent,
+	URIAppGenerateConsent,
+	URIAppConsentLogin,
+	URIAppConsumeConsent,
+	URIMarketfeedLTP,
+	URIMarketfeedOHLC,
+	URIMarketfeedQuote,
+	URIChartsHistorical,
+	URIChartsIntraday,
+	URIOptionchain,
+	URIOptionchainExpiryList,
+	URIHoldings,
+	URIPositions,
+	URIPositionConvert,
+	URIMarginCalculator,
+	URIMarginCalculatorMulti,
+	URIFundLimit,
+	URIProfile,
+	URIGetOrders,
+	URIPlaceOrder,
+	URIModifyPendingOrder,
+	URICancelPendingOrder,
+	URISliceOrder,
+	URIGetOrderStatus,
+	URIGetTrades,
+	URIGetTradesByOrder,
+	URILedger,
+	URITradeHistory,
+	URISuperOrders,
+	URIModifySuperOrder,
+	URICancelSuperOrderLeg,
+	URIForeverOrders,
+	URIModifyForeverOrder,
+	URICancelForeverOrder,
+	URIGetForeverOrders,
+	URIKillSwitch,
+	URIEDISTPIN,
+	URIEDISForm,
+	URIEDISInquire,
+}
+
+// ErrorResponse is the error body returned by DhanHQ, it is also returned
+// as an error so callers can inspect the code with errors.As
 type ErrorResponse struct {
 	ErrorType    string `json:"errorType"`
 	ErrorCode    string `json:"errorCode"`
 	ErrorMessage string `json:"errorMessage"`
 }
 
+func (e ErrorResponse) Error() string {
+	return fmt.Sprintf("API error: %s (Code: %s, Type: %s)", e.ErrorMessage, e.ErrorCode, e.ErrorType)
+}
+
+// checkResponse returns the DhanHQ error of a response without a 2xx status
+func checkResponse(resp HTTPResponse) error {
+	if resp.Response != nil && resp.Response.StatusCode >= 200 && resp.Response.StatusCode < 300 {
+		return nil
+	}
+	return apiErrorFromBody(resp.Body)
+}
+
+// apiErrorFromBody returns the ErrorResponse in body as an error, or a
+// generic error quoting the body when it is not a DhanHQ error
+func apiErrorFromBody(body []byte) error {
+	var errorResp ErrorResponse
+	if err := json.Unmarshal(body, &errorResp); err == nil && (errorResp.ErrorCode != "" || errorResp.ErrorMessage != "") {
+		return errorResp
+	}
+	return fmt.Errorf("unexpected response: %s", string(body))
+}
+
 // New creates a new DhanHQ API client with the provided parameters.
+// See NewClient for configuring anything beyond debug logging.
 func New(debug bool) *Client {
-	client := &Client{
+	return NewClient(WithDebug(debug))
+}
+
+// snapshot returns the current configuration, initializing the defaults
+// for a zero value Client
+func (c *Client) snapshot() *clientConfig {
+	if cfg := c.config.Load(); cfg != nil {
+		return cfg
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if cfg := c.config.Load(); cfg != nil {
+		return cfg
+	}
+	cfg := &clientConfig{
 		baseURI: baseURI,
 		authURI: authURI,
+		httpClient: NewHTTPClient(
+			&http.Client{},
+			false, // Default to no debug logging
+		),
+	}
+	c.config.Store(cfg)
+	return cfg
+}
+
+// update applies fn to a copy of the current configuration and swaps it in
+func (c *Client) update(fn func(cfg *clientConfig)) {
+	current := c.snapshot()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if latest := c.config.Load(); latest != nil {
+		current = latest
 	}
-	// Initialize the HTTP client
-	client.httpClient = NewHTTPClient(
-		&http.Client{},
-		debug, // Pass the debug flag to the HTTP client
-	)
+	next := *current
+	fn(&next)
+	c.config.Store(&next)
+}
 
-	return client
+// updateHTTPClient replaces the HTTP client with a modified copy so that
+// requests in flight keep using the one they started with
+func (c *Client) updateHTTPClient(fn func(h *httpClient)) {
+	c.update(func(cfg *clientConfig) {
+		h := *cfg.httpClient.GetClient()
+		fn(&h)
+		cfg.httpClient = &h
+	})
 }
 
 // Getters for Client fields
 
 func (c *Client) GetBaseURI() string {
-	return c.baseURI
+	return c.snapshot().baseURI
 }
 func (c *Client) GetAuthURI() string {
-	return c.authURI
+	return c.snapshot().authURI
 }
 func (c *Client) GetDhanClientId() string {
-	return c.dhanClientId
+	return c.snapshot().dhanClientId
 }
 func (c *Client) GetAccessToken() string {
-	return c.accessToken
+	return c.snapshot().accessToken
 }
 func (c *Client) GetPartnerId() string {
-	return c.partnerId
+	return c.snapshot().partnerId
 }
 
 // Setters for Client fields
 
 func (c *Client) SetBaseURI(baseURI string) {
-	c.baseURI = baseURI
+	c.update(func(cfg *clientConfig) { cfg.baseURI = baseURI })
 }
 func (c *Client) SetAuthURI(authURI string) {
-	c.authURI = authURI
+	c.update(func(cfg *clientConfig) { cfg.authURI = authURI })
 }
 func (c *Client) SetDhanClientId(dhanClientId string) {
-	c.dhanClientId = dhanClientId
+	c.update(func(cfg *clientConfig) { cfg.dhanClientId = dhanClientId })
 }
+
+// SetAccessToken sets an access token with an unknown expiry,
+// use SetAccessTokenWithExpiry to have the expiry tracked
 func (c *Client) SetAccessToken(accessToken string) {
-	c.accessToken = accessToken
+	c.SetAccessTokenWithExpiry(accessToken, time.Time{})
 }
 func (c *Client) SetPartnerId(partnerId string) {
-	c.partnerId = partnerId
+	c.update(func(cfg *clientConfig) { cfg.partnerId = partnerId })
+}
+
+// SetCredentials swaps the client id and access token together, so that no
+// request is ever sent with the client id of one account and the token of another
+func (c *Client) SetCredentials(dhanClientId, accessToken string) {
+	c.update(func(cfg *clientConfig) {
+		cfg.dhanClientId = dhanClientId
+		cfg.accessToken = accessToken
+		cfg.tokenExpiry = time.Time{}
+	})
+	c.tokens.renew(time.Time{})
 }
+
 func (c *Client) SetHTTPClient(h *http.Client, debug bool) {
-	// Implement a new HTTPClient interface that wraps the standard http.Client
-	c.httpClient = NewHTTPClient(
-		h,
-		debug, // Pass the debug flag to the HTTP client
-	)
+	c.update(func(cfg *clientConfig) {
+		previous := cfg.httpClient.GetClient()
+		// Implement a new HTTPClient interface that wraps the standard http.Client
+		next := NewHTTPClient(
+			h,
+			debug, // Pass the debug flag to the HTTP client
+		).GetClient()
+		next.logger = previous.logger
+		next.metrics = previous.metrics
+		next.tracer = previous.tracer
+		next.limiter = previous.limiter
+		next.retry = previous.retry
+		cfg.httpClient = next
+	})
+}
+
+// SetMetrics attaches a metrics collector that records every request made
+// by the client, pass nil to stop collecting
+func (c *Client) SetMetrics(m *Metrics) {
+	c.updateHTTPClient(func(h *httpClient) { h.metrics = m })
 }
 func (c *Client) GetHTTPClient() HTTPClient {
-	if c.httpClient == nil {
-		c.httpClient = NewHTTPClient(
-			&http.Client{},
-			true, // Default to no debug logging
-		)
-	}
-	return c.httpClient
+	return c.snapshot().httpClient
 }
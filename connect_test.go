@@ -0,0 +1,143 @@
+package dhanhq
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// These tests are meant to run with the race detector: go test -race ./...
+
+const concurrency = 8
+
+func TestConcurrentCredentialUpdates(t *testing.T) {
+	var unknownTokens atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("access-token"), "token-") {
+			unknownTokens.Add(1)
+		}
+		switch r.URL.Path {
+		case URIPositions:
+			w.Write([]byte(`[{"securityId":"1333","netQty":1}]`))
+		case URIFundLimit:
+			w.Write([]byte(`{"availabelBalance":1000}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	client := NewClient(WithBaseURI(srv.URL), WithCredentials("client-0", "token-0"))
+
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				client.SetAccessToken(fmt.Sprintf("token-%d-%d", i, j))
+				client.SetDhanClientId(fmt.Sprintf("client-%d-%d", i, j))
+				_ = client.GetAccessToken()
+				_ = client.GetDhanClientId()
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				if _, err := client.GetPositions(); err != nil {
+					t.Errorf("GetPositions: %v", err)
+				}
+				if _, err := client.GetFundLimit(); err != nil {
+					t.Errorf("GetFundLimit: %v", err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := unknownTokens.Load(); n > 0 {
+		t.Errorf("%d requests were sent without a token set by the test", n)
+	}
+}
+
+func TestSetCredentialsSwapsClientIdAndTokenTogether(t *testing.T) {
+	var mismatches atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			DhanClientId string `json:"dhanClientId"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decoding body: %v", err)
+		}
+		account := strings.TrimPrefix(body.DhanClientId, "client-")
+		if r.Header.Get("access-token") != "token-"+account {
+			mismatches.Add(1)
+		}
+		w.Write([]byte(`{"total_margin":"100.00","span_margin":"60.00","exposure_margin":"40.00"}`))
+	}))
+	defer srv.Close()
+
+	client := NewClient(WithBaseURI(srv.URL), WithCredentials("client-0", "token-0"))
+	legs := []Margin{{ExchangeSegment: ExchangeSegmentFNONSE, SecurityId: "35001", Quantity: 75}}
+
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				account := fmt.Sprintf("%d-%d", i, j)
+				client.SetCredentials("client-"+account, "token-"+account)
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				if _, err := client.CalculateBasketMargin(BasketMarginRequest{Legs: legs}); err != nil {
+					t.Errorf("CalculateBasketMargin: %v", err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := mismatches.Load(); n > 0 {
+		t.Errorf("%d requests mixed the client id of one account with the token of another", n)
+	}
+}
+
+func TestConcurrentClientConfiguration(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[]`))
+	}))
+	defer srv.Close()
+
+	client := NewClient(WithBaseURI(srv.URL), WithCredentials("client", "token"))
+
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				client.SetMetrics(NewMetrics())
+				client.SetHTTPClient(srv.Client(), false)
+				client.SetBaseURI(srv.URL)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				if _, err := client.GetPositions(); err != nil {
+					t.Errorf("GetPositions: %v", err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
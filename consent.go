@@ -0,0 +1,281 @@
+package dhanhq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// Consent statuses, GENERATED is returned by DhanHQ in GenerateConsentResponse
+// while the others are tracked locally by the ConsentManager
+const (
+	ConsentStatusGenerated = "GENERATED"
+	ConsentStatusConsumed  = "CONSUMED"
+	ConsentStatusExpired   = "EXPIRED"
+)
+
+// DefaultConsentTTL is how long a generated consent is assumed to be usable.
+// DhanHQ does not document the lifetime of a consent id, so adjust
+// ConsentConfig.TTL if your consents expire sooner.
+const DefaultConsentTTL = 10 * time.Minute
+
+var (
+	// ErrConsentExpired is matched by a *ConsentError for a consent that expired before it was consumed
+	ErrConsentExpired = errors.New("dhanhq: consent expired")
+
+	// ErrConsentUsed is matched by a *ConsentError for a consent that was already consumed
+	ErrConsentUsed = errors.New("dhanhq: consent already used")
+
+	// ErrConsentNotFound is matched by a *ConsentError for a consent id the manager did not generate
+	ErrConsentNotFound = errors.New("dhanhq: consent not found")
+
+	// ErrConsentRetriesExhausted is returned by ConsentManager.Login when every
+	// regenerated consent expired before the user logged in
+	ErrConsentRetriesExhausted = errors.New("dhanhq: consent regenerations exhausted")
+)
+
+// ConsentError is returned for a consent in the wrong state,
+// use errors.Is with ErrConsentExpired, ErrConsentUsed or ErrConsentNotFound
+type ConsentError struct {
+	ConsentId string
+	Status    string
+	Err       error
+}
+
+func (e *ConsentError) Error() string {
+	return fmt.Sprintf("%v (consent %s, status %s)", e.Err, e.ConsentId, e.Status)
+}
+
+func (e *ConsentError) Unwrap() error {
+	return e.Err
+}
+
+// Consent is a generated partner consent and its tracked status
+type Consent struct {
+	ConsentId string
+	Status    string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+// Expired reports whether the consent can no longer be consumed because it expired
+func (c Consent) Expired() bool {
+	return c.Status == ConsentStatusExpired ||
+		(c.Status == ConsentStatusGenerated && !time.Now().Before(c.ExpiresAt))
+}
+
+// ConsentConfig configures a ConsentManager
+type ConsentConfig struct {
+	// TTL is how long a consent is usable, DefaultConsentTTL by default
+	TTL time.Duration
+
+	// MaxRegenerations is how many times Login generates a new consent
+	// after the previous one expired
+	MaxRegenerations int
+
+	// OnRegenerate is called with every consent generated after the first one
+	OnRegenerate func(consent Consent)
+}
+
+// ConsentManager generates partner consents and tracks their status, so that
+// expired or used consents are detected before and after calling DhanHQ and
+// regenerated within a retry budget.
+//
+// DhanHQ has no API for the status of a consent, so the status is tracked
+// locally: a consent is assumed to expire after ConsentConfig.TTL and is only
+// known to be consumed when it was consumed through the manager or DhanHQ
+// rejected it. Expired and consumed consents are evicted when a new one is
+// generated.
+type ConsentManager struct {
+	client        *Client
+	partnerSecret string
+	config        ConsentConfig
+
+	mu       sync.Mutex
+	consents map[string]*Consent
+}
+
+// NewConsentManager creates a consent manager for the partner flow,
+// the partner id must be set on the client
+func (c *Client) NewConsentManager(partnerSecret string, config ConsentConfig) *ConsentManager {
+	if config.TTL <= 0 {
+		config.TTL = DefaultConsentTTL
+	}
+	return &ConsentManager{
+		client:        c,
+		partnerSecret: partnerSecret,
+		config:        config,
+		consents:      make(map[string]*Consent),
+	}
+}
+
+// Generate generates a new consent and starts tracking it
+func (m *ConsentManager) Generate(ctx context.Context) (Consent, error) {
+	resp, err := m.client.GenerateConsentContext(ctx, m.partnerSecret)
+	if err != nil {
+		return Consent{}, err
+	}
+
+	status := resp.ConsentStatus
+	if status == "" {
+		status = ConsentStatusGenerated
+	}
+	now := time.Now()
+	consent := &Consent{
+		ConsentId: resp.ConsentId,
+		Status:    status,
+		CreatedAt: now,
+		ExpiresAt: now.Add(m.config.TTL),
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.evict()
+	m.consents[consent.ConsentId] = consent
+	return *consent, nil
+}
+
+// Status returns the tracked status of a consent, it returns a *ConsentError
+// if the consent is unknown, expired or already used
+func (m *ConsentManager) Status(consentId string) (Consent, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.status(consentId)
+}
+
+// status checks a consent, m.mu must be held
+func (m *ConsentManager) status(consentId string) (Consent, error) {
+	consent, ok := m.consents[consentId]
+	if !ok {
+		return Consent{}, &ConsentError{ConsentId: consentId, Err: ErrConsentNotFound}
+	}
+	if consent.Expired() {
+		consent.Status = ConsentStatusExpired
+		return *consent, &ConsentError{ConsentId: consentId, Status: consent.Status, Err: ErrConsentExpired}
+	}
+	if consent.Status == ConsentStatusConsumed {
+		return *consent, &ConsentError{ConsentId: consentId, Status: consent.Status, Err: ErrConsentUsed}
+	}
+	return *consent, nil
+}
+
+// LoginURL returns the login URL of a tracked consent
+func (m *ConsentManager) LoginURL(consentId string) string {
+	return m.client.GenerateConsentLoginURL(consentId)
+}
+
+// Consume consumes the tokenId of a consent after checking its status,
+// DhanHQ errors for expired or used consents are returned as a *ConsentError
+func (m *ConsentManager) Consume(ctx context.Context, consentId, tokenId string) (ConsumeConsentResponse, error) {
+	if _, err := m.Status(consentId); err != nil {
+		return ConsumeConsentResponse{}, err
+	}
+
+	resp, err := m.client.ConsumeConsentContext(ctx, tokenId, m.partnerSecret)
+	if err != nil {
+		var apiErr ErrorResponse
+		if errors.As(err, &apiErr) {
+			if sentinel := consentErrorFor(apiErr); sentinel != nil {
+				status := ConsentStatusConsumed
+				if sentinel == ErrConsentExpired {
+					status = ConsentStatusExpired
+				}
+				m.setStatus(consentId, status)
+				return ConsumeConsentResponse{}, &ConsentError{ConsentId: consentId, Status: status, Err: fmt.Errorf("%w: %w", sentinel, err)}
+			}
+		}
+		return ConsumeConsentResponse{}, err
+	}
+	m.setStatus(consentId, ConsentStatusConsumed)
+	return resp, nil
+}
+
+// setStatus updates the status of a consent, unless it was evicted
+// while DhanHQ was being called
+func (m *ConsentManager) setStatus(consentId, status string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if consent, ok := m.consents[consentId]; ok {
+		consent.Status = status
+	}
+}
+
+// Phrases of the DhanHQ error messages about consents, DhanHQ has no
+// dedicated error codes for them
+var (
+	consentExpiredMessage = regexp.MustCompile(`(?i)\b(has |is )?expired\b`)
+	consentUsedMessage    = regexp.MustCompile(`(?i)\b(already (been )?(used|consumed)|consent (is |was )?consumed)\b`)
+)
+
+// consentErrorFor maps a DhanHQ error about a consent to a sentinel error,
+// matching whole phrases of the error message
+func consentErrorFor(e ErrorResponse) error {
+	switch {
+	case consentExpiredMessage.MatchString(e.ErrorMessage):
+		return ErrConsentExpired
+	case consentUsedMessage.MatchString(e.ErrorMessage):
+		return ErrConsentUsed
+	}
+	return nil
+}
+
+// Login generates a consent, waits for its tokenId with wait and consumes it,
+// generating a new consent when the previous one expires before the user
+// logs in, up to MaxRegenerations times. wait gets a context that is done
+// when the consent expires. The credentials are set on the client.
+//
+//	resp, err := manager.Login(ctx, func(ctx context.Context, loginURL string) (string, error) {
+//		return dhanhq.WaitForTokenId(ctx, loginURL, callbackConfig)
+//	})
+func (m *ConsentManager) Login(ctx context.Context, wait func(ctx context.Context, loginURL string) (string, error)) (ConsumeConsentResponse, error) {
+	for attempt := 0; attempt <= m.config.MaxRegenerations; attempt++ {
+		consent, err := m.Generate(ctx)
+		if err != nil {
+			return ConsumeConsentResponse{}, err
+		}
+		if attempt > 0 && m.config.OnRegenerate != nil {
+			m.config.OnRegenerate(consent)
+		}
+
+		waitCtx, cancel := context.WithDeadline(ctx, consent.ExpiresAt)
+		tokenId, err := wait(waitCtx, m.LoginURL(consent.ConsentId))
+		consentExpired := errors.Is(waitCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil
+		cancel()
+		if err != nil {
+			if consentExpired {
+				m.setStatus(consent.ConsentId, ConsentStatusExpired)
+				continue
+			}
+			return ConsumeConsentResponse{}, err
+		}
+
+		resp, err := m.Consume(ctx, consent.ConsentId, tokenId)
+		if errors.Is(err, ErrConsentExpired) {
+			continue
+		}
+		if err != nil {
+			return ConsumeConsentResponse{}, err
+		}
+		return resp, m.client.SetConsumedConsent(resp)
+	}
+	return ConsumeConsentResponse{}, ErrConsentRetriesExhausted
+}
+
+// Forget stops tracking consents that were consumed or have expired
+func (m *ConsentManager) Forget() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.evict()
+}
+
+// evict removes consumed and expired consents, m.mu must be held
+func (m *ConsentManager) evict() {
+	for id, consent := range m.consents {
+		if consent.Status == ConsentStatusConsumed || consent.Expired() {
+			delete(m.consents, id)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package dhanhq
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestConsentConsumeAfterEviction(t *testing.T) {
+	tests := []struct {
+		name    string
+		consume string
+		want    error
+	}{
+		{"consumed", `{"dhanClientId":"1000000001","accessToken":"token"}`, nil},
+		{"rejected as expired", `{"errorType":"Invalid_Authentication","errorCode":"DH-901","errorMessage":"Consent has expired"}`, ErrConsentExpired},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var manager *ConsentManager
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case URIPartnerGenerateConsent:
+					w.Write([]byte(`{"consentId":"consent","consentStatus":"GENERATED"}`))
+				case URIPartnerConsumeConsent:
+					// The consent expires and is evicted while DhanHQ handles the request
+					time.Sleep(20 * time.Millisecond)
+					manager.Forget()
+					w.Write([]byte(test.consume))
+				}
+			}))
+			defer srv.Close()
+
+			client := NewClient(WithAuthURI(srv.URL), WithPartnerId("partner"))
+			manager = client.NewConsentManager("secret", ConsentConfig{TTL: 10 * time.Millisecond})
+			consent, err := manager.Generate(context.Background())
+			if err != nil {
+				t.Fatalf("Generate: %v", err)
+			}
+
+			resp, err := manager.Consume(context.Background(), consent.ConsentId, "token")
+			if test.want == nil {
+				if err != nil || resp.AccessToken != "token" {
+					t.Errorf("Consume = %+v, %v, want the access token", resp, err)
+				}
+			} else if !errors.Is(err, test.want) {
+				t.Errorf("got %v, want %v", err, test.want)
+			}
+
+			if _, err := manager.Status(consent.ConsentId); !errors.Is(err, ErrConsentNotFound) {
+				t.Errorf("Status after eviction: got %v, want ErrConsentNotFound", err)
+			}
+		})
+	}
+}
+
+func TestConsentConsumeTracksStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case URIPartnerGenerateConsent:
+			w.Write([]byte(`{"consentId":"consent","consentStatus":"GENERATED"}`))
+		case URIPartnerConsumeConsent:
+			w.Write([]byte(`{"dhanClientId":"1000000001","accessToken":"token"}`))
+		}
+	}))
+	defer srv.Close()
+
+	manager := NewClient(WithAuthURI(srv.URL), WithPartnerId("partner")).NewConsentManager("secret", ConsentConfig{})
+	consent, err := manager.Generate(context.Background())
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if _, err := manager.Consume(context.Background(), consent.ConsentId, "token"); err != nil {
+		t.Fatalf("Consume: %v", err)
+	}
+	if _, err := manager.Consume(context.Background(), consent.ConsentId, "token"); !errors.Is(err, ErrConsentUsed) {
+		t.Errorf("second Consume: got %v, want ErrConsentUsed", err)
+	}
+	if _, err := manager.Consume(context.Background(), "unknown", "token"); !errors.Is(err, ErrConsentNotFound) {
+		t.Errorf("Consume of an unknown consent: got %v, want ErrConsentNotFound", err)
+	}
+}
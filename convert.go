@@ -0,0 +1,149 @@
+package dhanhq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// ViolationPositionNotFound is reported for a conversion of a position that is not open
+const ViolationPositionNotFound = "POSITION_NOT_FOUND"
+
+// ProductConversions lists the product types each product type can be converted to
+var ProductConversions = map[string][]string{
+	ProductTypeIntraday: {ProductTypeCNC, ProductTypeMargin, ProductTypeMTF},
+	ProductTypeCNC:      {ProductTypeIntraday, ProductTypeMTF},
+	ProductTypeMargin:   {ProductTypeIntraday},
+	ProductTypeMTF:      {ProductTypeCNC, ProductTypeIntraday},
+}
+
+// ConvertResult is the outcome of one conversion, Err is a *ValidationError
+// if the conversion was not sent
+type ConvertResult struct {
+	Request ConvertPositionRequest
+	Err     error
+}
+
+// ConvertRequestFor returns the request converting all of a position to toProductType
+func ConvertRequestFor(position Position, toProductType string) ConvertPositionRequest {
+	quantity := position.NetQty
+	if quantity < 0 {
+		quantity = -quantity
+	}
+	return ConvertPositionRequest{
+		FromProductType: position.ProductType,
+		ExchangeSegment: position.ExchangeSegment,
+		PositionType:    position.PositionType,
+		SecurityId:      position.SecurityId,
+		TradingSymbol:   position.TradingSymbol,
+		ConvertQty:      quantity,
+		ToProductType:   toProductType,
+	}
+}
+
+// ConvertPositions validates several conversions against the open positions
+// and sends the valid ones, one result per request in the same order. The
+// error is only set when the positions cannot be fetched.
+//
+//	positions, _ := client.GetPositions()
+//	var reqs []dhanhq.ConvertPositionRequest
+//	for _, position := range positions.Positions {
+//		if position.ProductType == dhanhq.ProductTypeIntraday && position.NetQty != 0 {
+//			reqs = append(reqs, dhanhq.ConvertRequestFor(position, dhanhq.ProductTypeCNC))
+//		}
+//	}
+//	results, err := client.ConvertPositions(ctx, reqs)
+func (c *Client) ConvertPositions(ctx context.Context, reqs []ConvertPositionRequest) ([]ConvertResult, error) {
+	positions, err := c.GetPositionsContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Conversions of the same position must fit its net quantity together
+	requested := make(map[string]int32)
+	for _, req := range reqs {
+		if req.ConvertQty > 0 {
+			requested[conversionKey(req)] += req.ConvertQty
+		}
+	}
+
+	results := make([]ConvertResult, len(reqs))
+	for i, req := range reqs {
+		results[i].Request = req
+		if err := validateConversion(req, positions, requested[conversionKey(req)]); err != nil {
+			results[i].Err = err
+			continue
+		}
+		results[i].Err = c.ConvertPositionContext(ctx, req)
+	}
+	return results, nil
+}
+
+// validateConversion checks a conversion against the open position it
+// converts, requested is the quantity of all the conversions of the position
+func validateConversion(req ConvertPositionRequest, positions Positions, requested int32) *ValidationError {
+	var violations []Violation
+	add := func(field, code, format string, args ...any) {
+		violations = append(violations, Violation{Field: field, Code: code, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if !slices.Contains(ProductConversions[req.FromProductType], req.ToProductType) {
+		add("toProductType", ViolationProductType, "%s cannot be converted to %s", req.FromProductType, req.ToProductType)
+	} else if allowed, ok := SegmentProductTypes[req.ExchangeSegment]; ok && !slices.Contains(allowed, req.ToProductType) {
+		add("toProductType", ViolationProductType, "%s is not allowed in %s", req.ToProductType, req.ExchangeSegment)
+	}
+
+	position, ok := findPosition(positions, req)
+	switch {
+	case !ok:
+		add("securityId", ViolationPositionNotFound, "no open %s position in %s", req.FromProductType, req.SecurityId)
+	case req.ConvertQty <= 0:
+		add("convertQty", ViolationQuantity, "must be positive")
+	default:
+		netQty := position.NetQty
+		if netQty < 0 {
+			netQty = -netQty
+		}
+		if req.ConvertQty > netQty {
+			add("convertQty", ViolationQuantity, "%d is above the net quantity %d", req.ConvertQty, netQty)
+		} else if requested > netQty {
+			add("convertQty", ViolationQuantity, "%d requested for the position in all is above the net quantity %d", requested, netQty)
+		}
+		if req.PositionType != "" && req.PositionType != position.PositionType {
+			add("positionType", ViolationPositionNotFound, "the position is %s, not %s", position.PositionType, req.PositionType)
+		}
+	}
+
+	if len(violations) == 0 {
+		return nil
+	}
+	return &ValidationError{ExchangeSegment: req.ExchangeSegment, SecurityId: req.SecurityId, Violations: violations}
+}
+
+// conversionKey identifies the position a conversion applies to
+func conversionKey(req ConvertPositionRequest) string {
+	return instrumentKey(req.ExchangeSegment, req.SecurityId) + ":" + req.FromProductType
+}
+
+// findPosition returns the open position a conversion applies to
+func findPosition(positions Positions, req ConvertPositionRequest) (Position, bool) {
+	for _, position := range positions.Positions {
+		if position.NetQty != 0 &&
+			position.ExchangeSegment == req.ExchangeSegment &&
+			position.SecurityId == req.SecurityId &&
+			position.ProductType == req.FromProductType {
+			return position, true
+		}
+	}
+	return Position{}, false
+}
+
+// ConvertErr joins the errors of the results, nil if every position was converted
+func ConvertErr(results []ConvertResult) error {
+	var errs []error
+	for _, result := range results {
+		errs = append(errs, result.Err)
+	}
+	return errors.Join(errs...)
+}
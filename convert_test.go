@@ -0,0 +1,112 @@
+package dhanhq
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+)
+
+var conversionPositions = Positions{Positions: []Position{
+	{SecurityId: "1333", ExchangeSegment: ExchangeSegmentEquityNSE, ProductType: ProductTypeIntraday, PositionType: "LONG", NetQty: 100},
+	{SecurityId: "11536", ExchangeSegment: ExchangeSegmentEquityNSE, ProductType: ProductTypeIntraday, PositionType: "SHORT", NetQty: -40},
+	{SecurityId: "35001", ExchangeSegment: ExchangeSegmentFNONSE, ProductType: ProductTypeIntraday, PositionType: "LONG", NetQty: 75},
+	{SecurityId: "2885", ExchangeSegment: ExchangeSegmentEquityNSE, ProductType: ProductTypeIntraday, PositionType: "CLOSED", NetQty: 0},
+}}
+
+func TestValidateConversion(t *testing.T) {
+	toCNC := ConvertRequestFor(conversionPositions.Positions[0], ProductTypeCNC)
+	with := func(fn func(req *ConvertPositionRequest)) ConvertPositionRequest {
+		req := toCNC
+		fn(&req)
+		return req
+	}
+	tests := []struct {
+		name      string
+		req       ConvertPositionRequest
+		requested int32
+		want      []string
+	}{
+		{"whole position", toCNC, 100, nil},
+		{"part of the position", with(func(r *ConvertPositionRequest) { r.ConvertQty = 60 }), 60, nil},
+		{"short position", ConvertRequestFor(conversionPositions.Positions[1], ProductTypeCNC), 40, nil},
+		{"above the net quantity", with(func(r *ConvertPositionRequest) { r.ConvertQty = 101 }), 101, []string{ViolationQuantity}},
+		{"above the net quantity in all", with(func(r *ConvertPositionRequest) { r.ConvertQty = 60 }), 120, []string{ViolationQuantity}},
+		{"zero quantity", with(func(r *ConvertPositionRequest) { r.ConvertQty = 0 }), 0, []string{ViolationQuantity}},
+		{"wrong position type", with(func(r *ConvertPositionRequest) { r.PositionType = "SHORT" }), 100, []string{ViolationPositionNotFound}},
+		{"no position type", with(func(r *ConvertPositionRequest) { r.PositionType = "" }), 100, nil},
+		{"closed position", ConvertRequestFor(conversionPositions.Positions[3], ProductTypeCNC), 0, []string{ViolationPositionNotFound}},
+		{"other product type", with(func(r *ConvertPositionRequest) {
+			r.FromProductType, r.ToProductType = ProductTypeCNC, ProductTypeIntraday
+		}), 100, []string{ViolationPositionNotFound}},
+		{"disallowed transition", with(func(r *ConvertPositionRequest) { r.ToProductType = ProductTypeBO }), 100, []string{ViolationProductType}},
+		{"same product type", with(func(r *ConvertPositionRequest) { r.ToProductType = ProductTypeIntraday }), 100, []string{ViolationProductType}},
+		{"not allowed in the segment", ConvertRequestFor(conversionPositions.Positions[2], ProductTypeCNC), 75, []string{ViolationProductType}},
+		{"allowed in the segment", ConvertRequestFor(conversionPositions.Positions[2], ProductTypeMargin), 75, nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := validateConversion(test.req, conversionPositions, test.requested)
+			var codes []string
+			if err != nil {
+				for _, violation := range err.Violations {
+					codes = append(codes, violation.Code)
+				}
+			}
+			if !slices.Equal(codes, test.want) {
+				t.Errorf("violations are %v, want %v (%v)", codes, test.want, err)
+			}
+		})
+	}
+}
+
+func TestConvertPositions(t *testing.T) {
+	var mu sync.Mutex
+	var converted []ConvertPositionRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == URIPositions:
+			json.NewEncoder(w).Encode(conversionPositions.Positions)
+		case r.Method == http.MethodPost && r.URL.Path == URIPositionConvert:
+			var req ConvertPositionRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			mu.Lock()
+			converted = append(converted, req)
+			mu.Unlock()
+			w.WriteHeader(http.StatusAccepted)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+	client := NewClient(WithBaseURI(srv.URL), WithCredentials("client", "token"))
+
+	half := ConvertRequestFor(conversionPositions.Positions[0], ProductTypeCNC)
+	half.ConvertQty = 60
+	other := ConvertRequestFor(conversionPositions.Positions[1], ProductTypeCNC)
+	results, err := client.ConvertPositions(context.Background(), []ConvertPositionRequest{half, half, other})
+	if err != nil {
+		t.Fatalf("ConvertPositions: %v", err)
+	}
+
+	// Two conversions of 60 do not fit a net quantity of 100 together, so neither is sent
+	var validationErr *ValidationError
+	for _, i := range []int{0, 1} {
+		if !errors.As(results[i].Err, &validationErr) || !validationErr.Has(ViolationQuantity) {
+			t.Errorf("result %d error is %v, want a quantity violation", i, results[i].Err)
+		}
+	}
+	if results[2].Err != nil {
+		t.Errorf("result 2 error is %v", results[2].Err)
+	}
+	if len(converted) != 1 || converted[0].SecurityId != "11536" || converted[0].ConvertQty != 40 || converted[0].DhanClientId != "client" {
+		t.Errorf("converted %+v, want only the 40 of 11536", converted)
+	}
+	if err := ConvertErr(results); !errors.As(err, &validationErr) {
+		t.Errorf("ConvertErr = %v, want the validation errors", err)
+	}
+}
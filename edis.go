@@ -0,0 +1,185 @@
+package dhanhq
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// EDISAllISINs is the ISIN of the eDIS inquiry of every holding, see InquireAllEDIS
+const EDISAllISINs = "ALL"
+
+// EDISFormRequest asks for the CDSL form approving the sale of a holding,
+// Bulk approves every holding at once
+type EDISFormRequest struct {
+	ISIN     string `json:"isin"`
+	Qty      int32  `json:"qty"`
+	Exchange string `json:"exchange"`
+	Segment  string `json:"segment"`
+	Bulk     bool   `json:"bulk"`
+}
+
+// EDISFormRequestFor returns the form request approving the sale of quantity
+// shares of a holding, all of its AvailableQty if quantity is not positive
+func EDISFormRequestFor(holding Holding, quantity int32) EDISFormRequest {
+	if quantity <= 0 {
+		quantity = holding.AvailableQty
+	}
+	exchange := holding.Exchange
+	if exchange != "BSE" {
+		exchange = "NSE"
+	}
+	return EDISFormRequest{ISIN: holding.ISIN, Qty: quantity, Exchange: exchange, Segment: "EQ"}
+}
+
+// EDISForm is the CDSL form to show to the user, who approves the sale with
+// their T-PIN on it
+type EDISForm struct {
+	DhanClientId string `json:"dhanClientId"`
+	EDISFormHTML string `json:"edisFormHtml"`
+}
+
+// EDISStatus is the eDIS approval of a holding
+type EDISStatus struct {
+	ClientId    string `json:"clientId"`
+	ISIN        string `json:"isin"`
+	TotalQty    int32  `json:"totalQty"`
+	ApprovedQty int32  `json:"aprvdQty"`
+	Status      string `json:"status"`
+	Remarks     string `json:"remarks"`
+}
+
+// Covers reports whether quantity shares are approved for sale
+func (s EDISStatus) Covers(quantity int32) bool {
+	return quantity > 0 && s.ApprovedQty >= quantity
+}
+
+// GenerateTPIN sends a T-PIN to the registered mobile number and email of the
+// user, it is needed to sell holdings without DDPI
+func (c *Client) GenerateTPIN() error {
+	return c.GenerateTPINContext(context.Background())
+}
+
+// GenerateTPINContext is GenerateTPIN with a context for cancellation and tracing
+func (c *Client) GenerateTPINContext(ctx context.Context) (err error) {
+	ctx, span := c.startSpan(ctx, "GenerateTPIN")
+	defer func() { endSpan(span, err) }()
+
+	cfg, err := c.authorize(ctx)
+	if err != nil {
+		return err
+	}
+	headers := http.Header{
+		"Accept":       {"application/json"},
+		"access-token": {cfg.accessToken},
+	}
+	resp, err := cfg.httpClient.DoContext(ctx, http.MethodGet, cfg.baseURI+URIEDISTPIN, headers, nil)
+	if err != nil {
+		return err
+	}
+	return checkResponse(resp)
+}
+
+// GetEDISForm retrieves the HTML form on which the user enters the T-PIN to
+// approve the sale of a holding, it has to be rendered in a browser
+//
+//	err := client.GenerateTPIN()
+//	form, err := client.GetEDISForm(dhanhq.EDISFormRequestFor(holding, 0))
+func (c *Client) GetEDISForm(req EDISFormRequest) (EDISForm, error) {
+	return c.GetEDISFormContext(context.Background(), req)
+}
+
+// GetEDISFormContext is GetEDISForm with a context for cancellation and tracing
+func (c *Client) GetEDISFormContext(ctx context.Context, req EDISFormRequest) (_ EDISForm, err error) {
+	ctx, span := c.startSpan(ctx, "GetEDISForm")
+	defer func() { endSpan(span, err) }()
+
+	cfg, err := c.authorize(ctx)
+	if err != nil {
+		return EDISForm{}, err
+	}
+	headers := http.Header{
+		"Content-Type": {"application/json"},
+		"Accept":       {"application/json"},
+		"access-token": {cfg.accessToken},
+	}
+	resp, err := cfg.httpClient.DoJSONContext(ctx, http.MethodPost, cfg.baseURI+URIEDISForm, nil, req, headers, nil)
+	if err != nil {
+		return EDISForm{}, err
+	}
+	if err := checkResponse(resp); err != nil {
+		return EDISForm{}, err
+	}
+
+	var form EDISForm
+	if err := json.Unmarshal(resp.Body, &form); err != nil {
+		return EDISForm{}, err
+	}
+	return form, nil
+}
+
+// InquireEDIS retrieves the eDIS approval of the holding with the given ISIN,
+// use InquireAllEDIS for the approvals of every holding
+func (c *Client) InquireEDIS(isin string) (EDISStatus, error) {
+	return c.InquireEDISContext(context.Background(), isin)
+}
+
+// InquireEDISContext is InquireEDIS with a context for cancellation and tracing
+func (c *Client) InquireEDISContext(ctx context.Context, isin string) (_ EDISStatus, err error) {
+	ctx, span := c.startSpan(ctx, "InquireEDIS")
+	defer func() { endSpan(span, err) }()
+
+	body, err := c.inquireEDIS(ctx, isin)
+	if err != nil {
+		return EDISStatus{}, err
+	}
+	var status EDISStatus
+	if err := json.Unmarshal(body, &status); err != nil {
+		return EDISStatus{}, err
+	}
+	return status, nil
+}
+
+// InquireAllEDIS retrieves the eDIS approvals of every holding
+func (c *Client) InquireAllEDIS() ([]EDISStatus, error) {
+	return c.InquireAllEDISContext(context.Background())
+}
+
+// InquireAllEDISContext is InquireAllEDIS with a context for cancellation and tracing
+func (c *Client) InquireAllEDISContext(ctx context.Context) (_ []EDISStatus, err error) {
+	ctx, span := c.startSpan(ctx, "InquireAllEDIS")
+	defer func() { endSpan(span, err) }()
+
+	body, err := c.inquireEDIS(ctx, EDISAllISINs)
+	if err != nil {
+		return nil, err
+	}
+	var statuses []EDISStatus
+	if err := json.Unmarshal(body, &statuses); err != nil {
+		return nil, err
+	}
+	return statuses, nil
+}
+
+// inquireEDIS fetches the eDIS inquiry of an ISIN or EDISAllISINs
+func (c *Client) inquireEDIS(ctx context.Context, isin string) ([]byte, error) {
+	cfg, err := c.authorize(ctx)
+	if err != nil {
+		return nil, err
+	}
+	headers := http.Header{
+		"Accept":       {"application/json"},
+		"access-token": {cfg.accessToken},
+	}
+	rURL := cfg.baseURI + fmt.Sprintf(URIEDISInquire, url.PathEscape(isin))
+	resp, err := cfg.httpClient.DoContext(ctx, http.MethodGet, rURL, headers, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkResponse(resp); err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
@@ -0,0 +1,38 @@
+package dhanhq
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestInquireEDIS(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/edis/inquire/INE040A01034":
+			w.Write([]byte(`{"clientId":"client","isin":"INE040A01034","totalQty":10,"aprvdQty":10,"status":"SUCCESS"}`))
+		case "/edis/inquire/ALL":
+			w.Write([]byte(`[
+				{"clientId":"client","isin":"INE040A01034","totalQty":10,"aprvdQty":10,"status":"SUCCESS"},
+				{"clientId":"client","isin":"INE467B01029","totalQty":5,"aprvdQty":0,"status":"PENDING"}
+			]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+	client := NewClient(WithBaseURI(srv.URL), WithCredentials("client", "token"))
+
+	status, err := client.InquireEDIS("INE040A01034")
+	if err != nil || !status.Covers(10) || status.Covers(11) {
+		t.Errorf("InquireEDIS = %+v, %v, want 10 approved", status, err)
+	}
+
+	statuses, err := client.InquireAllEDIS()
+	if err != nil {
+		t.Fatalf("InquireAllEDIS: %v", err)
+	}
+	if len(statuses) != 2 || statuses[1].ISIN != "INE467B01029" || statuses[1].Covers(1) {
+		t.Errorf("InquireAllEDIS = %+v, want both holdings", statuses)
+	}
+}
@@ -0,0 +1,66 @@
+/*
+This is an example for the individual (API key) consent flow,
+use it if you trade on your own account with the app_id and app_secret
+generated on web.dhan.co instead of a partner id and secret
+*/
+
+package main
+
+import (
+	"fmt"
+	dhanhq "github.com/tradewithcanvas/godhanhq"
+)
+
+const (
+	// Your Dhan client id along with the API key and secret for DhanHQ API
+	// Replace these with your actual client id, app id and app secret
+	dhanClientId = "your_dhan_client_id"
+	appId        = "your_app_id"
+	appSecret    = "your_app_secret"
+)
+
+func main() {
+
+	// Create a new DhanHQ client with the client ID
+	dhanClient := dhanhq.NewClient(dhanhq.WithDhanClientId(dhanClientId))
+
+	// Generate Consent
+	consentResponse, err := dhanClient.GenerateAppConsent(appId, appSecret)
+	if err != nil {
+		fmt.Println("Error generating consent:", err)
+		return
+	}
+
+	fmt.Printf("Consent App ID: %s, Consent Status: %s\n", consentResponse.ConsentAppId, consentResponse.ConsentAppStatus)
+
+	// Log in on the web ui, you are redirected to your redirect URL with the tokenId
+	url := dhanClient.GenerateAppConsentLoginURL(consentResponse.ConsentAppId)
+	fmt.Println("Consent login URL:", url)
+
+	// Ask for the tokenID
+	var tokenId string
+	fmt.Print("Enter the token ID obtained from the consent login: ")
+	fmt.Scanf("%s", &tokenId)
+
+	if tokenId == "" {
+		fmt.Println("Token ID cannot be empty")
+		return
+	}
+
+	consumeResponse, err := dhanClient.ConsumeAppConsent(tokenId, appId, appSecret)
+	if err != nil {
+		fmt.Println("Error consuming consent:", err)
+		return
+	}
+
+	// Use the consumed consent for further requests, this also tracks the token expiry
+	if err := dhanClient.SetConsumedConsent(consumeResponse); err != nil {
+		fmt.Println("Error setting credentials:", err)
+		return
+	}
+
+	fmt.Println("Consent consumed successfully:")
+	fmt.Println("Client ID:", consumeResponse.DhanClientId)
+	fmt.Println("Client Name:", consumeResponse.DhanClientName)
+	fmt.Println("Token Expiry:", dhanClient.Tokens().Expiry())
+}
@@ -58,6 +58,12 @@ func main() {
 		return
 	}
 
+	// Use the consumed consent for further requests, this also tracks the token expiry
+	if err := dhanClient.SetConsumedConsent(consumeResponse); err != nil {
+		fmt.Println("Error setting credentials:", err)
+		return
+	}
+
 	// Print the details of the consumed consent
 	fmt.Println("Consent consumed successfully:")
 	fmt.Println("Client ID:", consumeResponse.DhanClientId)
@@ -65,4 +71,5 @@ func main() {
 	fmt.Println("Client UCC:", consumeResponse.DhanClientUcc)
 	fmt.Println("Given Power of Attorney:", consumeResponse.GivenPowerOfAttorney)
 	fmt.Println("Access Token:", consumeResponse.AccessToken)
+	fmt.Println("Token Expiry:", dhanClient.Tokens().Expiry())
 }
@@ -12,11 +12,10 @@ const (
 
 func main() {
 
-	dhanClient := dhanhq.New(false) // true enables debug logging
-
 	// Set the access token and clientId for the Dhan client
-	dhanClient.SetAccessToken(accessToken)
-	dhanClient.SetDhanClientId(dhanClientId)
+	dhanClient := dhanhq.NewClient(
+		dhanhq.WithCredentials(dhanClientId, accessToken),
+	)
 
 	// Get the margins for a given security and transaction type
 	input := dhanhq.Margin{
@@ -11,10 +11,11 @@ const (
 )
 
 func main() {
-	dhanClient := dhanhq.New(true) // true enables debug logging
-	// Set the access token for the Dhan client
-	dhanClient.SetAccessToken(accessToken)
-	dhanClient.SetDhanClientId(dhanClientId)
+	// Set the access token and clientId for the Dhan client
+	dhanClient := dhanhq.NewClient(
+		dhanhq.WithCredentials(dhanClientId, accessToken),
+		dhanhq.WithDebug(true), // true enables debug logging
+	)
 
 	// Get the LTP for a list of securities
 	marketInput := dhanhq.MarketDataInput{
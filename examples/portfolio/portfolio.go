@@ -10,9 +10,10 @@ const (
 )
 
 func main() {
-	dhanClient := dhanhq.New(true) // true enables debug logging
-	// Set the access token for the Dhan client
-	dhanClient.SetAccessToken(accessToken)
+	dhanClient := dhanhq.NewClient(
+		dhanhq.WithAccessToken(accessToken),
+		dhanhq.WithDebug(true), // true enables debug logging
+	)
 
 	// Get the Positions for a client
 	positions, err := dhanClient.GetPositions()
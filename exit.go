@@ -0,0 +1,214 @@
+package dhanhq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// PositionFilter selects positions by any combination of fields, an empty
+// field matches every position
+type PositionFilter struct {
+	ExchangeSegments []string
+	ProductTypes     []string
+	PositionTypes    []string
+	SecurityIds      []string
+
+	// Underlyings matches the underlying symbol from the instrument master,
+	// or the trading symbol up to its first dash if the position is not in it
+	Underlyings []string
+}
+
+// Match reports whether the position passes the filter, master may be nil
+func (f PositionFilter) Match(position Position, master *InstrumentMaster) bool {
+	matches := func(values []string, value string) bool {
+		return len(values) == 0 || slices.Contains(values, value)
+	}
+	return matches(f.ExchangeSegments, position.ExchangeSegment) &&
+		matches(f.ProductTypes, position.ProductType) &&
+		matches(f.PositionTypes, position.PositionType) &&
+		matches(f.SecurityIds, position.SecurityId) &&
+		matches(f.Underlyings, positionUnderlying(position, master))
+}
+
+// positionUnderlying returns the underlying symbol of a position
+func positionUnderlying(position Position, master *InstrumentMaster) string {
+	if master != nil {
+		if instrument, ok := master.Lookup(position.ExchangeSegment, position.SecurityId); ok && instrument.UnderlyingSymbol != "" {
+			return instrument.UnderlyingSymbol
+		}
+	}
+	underlying, _, _ := strings.Cut(position.TradingSymbol, "-")
+	return underlying
+}
+
+// ExitRequest selects the positions to close and how to close them
+type ExitRequest struct {
+	Filter PositionFilter
+
+	// OrderType is OrderTypeMarket, the default, or OrderTypeLimit
+	OrderType string
+
+	// LimitPrice returns the price of the limit order closing a position,
+	// it is required with OrderTypeLimit
+	LimitPrice func(position Position) float64
+
+	// Instruments, if set, provides the underlyings for the filter and the
+	// freeze quantities. Orders are sent with SliceOrder unless the master
+	// shows they are within the freeze quantity.
+	Instruments *InstrumentMaster
+}
+
+// ExitResult is the outcome of closing one position, Order is the reverse
+// order closing it, or the trade the exit legs make for bracket and cover
+// orders
+type ExitResult struct {
+	Position Position
+	Order    OrderRequest
+	Orders   []OrderResponse
+	Err      error
+}
+
+// ExitPositions closes the open positions matching the filter, one result
+// per position. Bracket and cover order positions are closed by modifying
+// their pending stop loss legs to market orders, the others with reverse
+// orders. The error is only set when the positions or the order book cannot
+// be fetched, the errors of single positions are in their results.
+func (c *Client) ExitPositions(ctx context.Context, req ExitRequest) ([]ExitResult, error) {
+	positions, err := c.GetPositionsContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return c.exitPositions(ctx, positions, req)
+}
+
+// ExitAllPositions closes every open position with market orders
+func (c *Client) ExitAllPositions(ctx context.Context) ([]ExitResult, error) {
+	return c.ExitPositions(ctx, ExitRequest{})
+}
+
+// exitPositions closes the matching positions of an already fetched list
+func (c *Client) exitPositions(ctx context.Context, positions Positions, req ExitRequest) ([]ExitResult, error) {
+	orderType := req.OrderType
+	if orderType == "" {
+		orderType = OrderTypeMarket
+	}
+	if orderType == OrderTypeLimit && req.LimitPrice == nil {
+		return nil, errors.New("dhanhq: exiting with limit orders needs a LimitPrice")
+	}
+
+	var open []Position
+	var orders []Order
+	for _, position := range positions.Positions {
+		if position.NetQty == 0 || !req.Filter.Match(position, req.Instruments) {
+			continue
+		}
+		if isLegProduct(position.ProductType) && orders == nil {
+			var err error
+			if orders, err = c.GetOrdersContext(ctx); err != nil {
+				return nil, err
+			}
+		}
+		open = append(open, position)
+	}
+
+	var results []ExitResult
+	for _, position := range open {
+		order := OrderRequest{
+			TransactionType: TransactionTypeSell,
+			ExchangeSegment: position.ExchangeSegment,
+			ProductType:     position.ProductType,
+			OrderType:       orderType,
+			Validity:        ValidityDay,
+			SecurityId:      position.SecurityId,
+			Quantity:        position.NetQty,
+		}
+		if position.NetQty < 0 {
+			order.TransactionType = TransactionTypeBuy
+			order.Quantity = -position.NetQty
+		}
+		if isLegProduct(position.ProductType) {
+			order.OrderType = OrderTypeMarket
+			result := ExitResult{Position: position, Order: order}
+			result.Orders, result.Err = c.exitLegs(ctx, position, orders)
+			results = append(results, result)
+			continue
+		}
+		if orderType == OrderTypeLimit {
+			order.Price = req.LimitPrice(position)
+		}
+
+		result := ExitResult{Position: position, Order: order}
+		if withinFreezeQuantity(order, req.Instruments) {
+			var resp OrderResponse
+			resp, result.Err = c.PlaceOrderContext(ctx, order)
+			if result.Err == nil {
+				result.Orders = []OrderResponse{resp}
+			}
+		} else {
+			result.Orders, result.Err = c.SliceOrderContext(ctx, order)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// isLegProduct reports whether a product is a bracket or cover order, whose
+// positions are closed through their stop loss legs
+func isLegProduct(productType string) bool {
+	return productType == ProductTypeBO || productType == ProductTypeCO
+}
+
+// exitLegs closes a bracket or cover order position by modifying its pending
+// stop loss legs to market orders, DhanHQ then cancels the target legs
+func (c *Client) exitLegs(ctx context.Context, position Position, orders []Order) ([]OrderResponse, error) {
+	var responses []OrderResponse
+	var errs []error
+	for _, order := range orders {
+		if !order.Pending() || order.LegName != LegNameStopLoss || order.ProductType != position.ProductType ||
+			order.ExchangeSegment != position.ExchangeSegment || order.SecurityId != position.SecurityId {
+			continue
+		}
+		quantity := order.RemainingQuantity
+		if quantity <= 0 {
+			quantity = order.Quantity
+		}
+		resp, err := c.ModifyOrderContext(ctx, ModifyOrderRequest{
+			OrderId:   order.OrderId,
+			OrderType: OrderTypeMarket,
+			LegName:   LegNameStopLoss,
+			Quantity:  quantity,
+			Validity:  ValidityDay,
+		})
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		responses = append(responses, resp)
+	}
+	if len(responses) == 0 && len(errs) == 0 {
+		return nil, fmt.Errorf("dhanhq: no pending stop loss leg to exit the %s position in %s", position.ProductType, position.TradingSymbol)
+	}
+	return responses, errors.Join(errs...)
+}
+
+// withinFreezeQuantity reports whether an order is known to be within the
+// freeze quantity of its instrument, so that it does not need slicing
+func withinFreezeQuantity(order OrderRequest, master *InstrumentMaster) bool {
+	if master == nil {
+		return false
+	}
+	instrument, ok := master.Lookup(order.ExchangeSegment, order.SecurityId)
+	return ok && instrument.FreezeQuantity > 0 && order.Quantity <= instrument.FreezeQuantity
+}
+
+// ExitErr joins the errors of the results, nil if every position was closed
+func ExitErr(results []ExitResult) error {
+	var errs []error
+	for _, result := range results {
+		errs = append(errs, result.Err)
+	}
+	return errors.Join(errs...)
+}
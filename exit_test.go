@@ -0,0 +1,185 @@
+package dhanhq
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// exitBroker serves fixed positions and orders, and records the orders
+// placed, sliced and modified to exit them
+type exitBroker struct {
+	t         *testing.T
+	positions string
+	orders    string
+
+	mu       sync.Mutex
+	calls    []string
+	modified []ModifyOrderRequest
+	placed   []OrderRequest
+}
+
+func (b *exitBroker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.calls = append(b.calls, r.Method+" "+r.URL.Path)
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == URIPositions:
+		w.Write([]byte(b.positions))
+	case r.Method == http.MethodGet && r.URL.Path == URIGetOrders:
+		w.Write([]byte(b.orders))
+	case r.Method == http.MethodPut && strings.HasPrefix(r.URL.Path, "/orders/"):
+		var req ModifyOrderRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			b.t.Errorf("decoding modification: %v", err)
+		}
+		b.modified = append(b.modified, req)
+		json.NewEncoder(w).Encode(OrderResponse{OrderId: req.OrderId, OrderStatus: OrderStatusTransit})
+	case r.Method == http.MethodPost && (r.URL.Path == URIPlaceOrder || r.URL.Path == URISliceOrder):
+		var req OrderRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			b.t.Errorf("decoding order: %v", err)
+		}
+		b.placed = append(b.placed, req)
+		if r.URL.Path == URISliceOrder {
+			w.Write([]byte(`[{"orderId":"10","orderStatus":"TRANSIT"},{"orderId":"11","orderStatus":"TRANSIT"}]`))
+			return
+		}
+		w.Write([]byte(`{"orderId":"10","orderStatus":"TRANSIT"}`))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func newExitTest(t *testing.T, broker *exitBroker) *Client {
+	t.Helper()
+	broker.t = t
+	srv := httptest.NewServer(broker)
+	t.Cleanup(srv.Close)
+	return NewClient(WithBaseURI(srv.URL), WithCredentials("client", "token"))
+}
+
+func TestExitPositionsModifiesStopLossLegs(t *testing.T) {
+	broker := &exitBroker{
+		positions: `[
+			{"securityId":"1333","tradingSymbol":"HDFCBANK","exchangeSegment":"NSE_EQ","productType":"BO","netQty":50},
+			{"securityId":"11536","tradingSymbol":"TCS","exchangeSegment":"NSE_EQ","productType":"CO","netQty":-20}
+		]`,
+		orders: `[
+			{"orderId":"1","orderStatus":"PENDING","legName":"STOP_LOSS_LEG","productType":"BO","exchangeSegment":"NSE_EQ","securityId":"1333","quantity":50},
+			{"orderId":"2","orderStatus":"PENDING","legName":"TARGET_LEG","productType":"BO","exchangeSegment":"NSE_EQ","securityId":"1333","quantity":50},
+			{"orderId":"3","orderStatus":"TRADED","legName":"STOP_LOSS_LEG","productType":"BO","exchangeSegment":"NSE_EQ","securityId":"1333","quantity":50},
+			{"orderId":"4","orderStatus":"PENDING","legName":"STOP_LOSS_LEG","productType":"BO","exchangeSegment":"NSE_EQ","securityId":"2885","quantity":10},
+			{"orderId":"5","orderStatus":"PENDING","legName":"STOP_LOSS_LEG","productType":"INTRADAY","exchangeSegment":"NSE_EQ","securityId":"1333","quantity":50},
+			{"orderId":"6","orderStatus":"PART_TRADED","legName":"STOP_LOSS_LEG","productType":"CO","exchangeSegment":"NSE_EQ","securityId":"11536","quantity":50,"remainingQuantity":20}
+		]`,
+	}
+	client := newExitTest(t, broker)
+
+	results, err := client.ExitAllPositions(context.Background())
+	if err != nil {
+		t.Fatalf("ExitAllPositions: %v", err)
+	}
+	if err := ExitErr(results); err != nil {
+		t.Fatalf("exit failed: %v", err)
+	}
+	if len(results) != 2 || len(results[0].Orders) != 1 || len(results[1].Orders) != 1 {
+		t.Fatalf("results are %+v, want one modified leg per position", results)
+	}
+
+	// The BO leg has no remaining quantity so its quantity is used
+	want := []ModifyOrderRequest{
+		{DhanClientId: "client", OrderId: "1", OrderType: OrderTypeMarket, LegName: LegNameStopLoss, Quantity: 50, Validity: ValidityDay},
+		{DhanClientId: "client", OrderId: "6", OrderType: OrderTypeMarket, LegName: LegNameStopLoss, Quantity: 20, Validity: ValidityDay},
+	}
+	if !slices.Equal(broker.modified, want) {
+		t.Errorf("modifications are\n%+v\nwant\n%+v", broker.modified, want)
+	}
+	if len(broker.placed) != 0 {
+		t.Errorf("placed reverse orders %+v for bracket and cover positions", broker.placed)
+	}
+	if n := slices.Index(broker.calls, "GET "+URIGetOrders); n < 0 || slices.Index(broker.calls[n+1:], "GET "+URIGetOrders) >= 0 {
+		t.Errorf("calls are %v, want the order book fetched once", broker.calls)
+	}
+}
+
+func TestExitPositionsWithoutPendingStopLossLeg(t *testing.T) {
+	broker := &exitBroker{
+		positions: `[{"securityId":"1333","tradingSymbol":"HDFCBANK","exchangeSegment":"NSE_EQ","productType":"BO","netQty":50}]`,
+		orders: `[
+			{"orderId":"2","orderStatus":"PENDING","legName":"TARGET_LEG","productType":"BO","exchangeSegment":"NSE_EQ","securityId":"1333","quantity":50},
+			{"orderId":"3","orderStatus":"CANCELLED","legName":"STOP_LOSS_LEG","productType":"BO","exchangeSegment":"NSE_EQ","securityId":"1333","quantity":50}
+		]`,
+	}
+	client := newExitTest(t, broker)
+
+	results, err := client.ExitAllPositions(context.Background())
+	if err != nil {
+		t.Fatalf("ExitAllPositions: %v", err)
+	}
+	if len(results) != 1 || results[0].Err == nil || !strings.Contains(results[0].Err.Error(), "no pending stop loss leg") {
+		t.Fatalf("results are %+v, want the missing leg error", results)
+	}
+	if len(broker.modified) != 0 || len(broker.placed) != 0 {
+		t.Errorf("sent %+v and %+v without a pending stop loss leg", broker.modified, broker.placed)
+	}
+}
+
+func TestExitPositionsSlicesUnlessWithinFreezeQuantity(t *testing.T) {
+	master := NewInstrumentMaster(
+		Instrument{ExchangeSegment: ExchangeSegmentFNONSE, SecurityId: "35001", LotSize: 75, FreezeQuantity: 1800},
+		Instrument{ExchangeSegment: ExchangeSegmentFNONSE, SecurityId: "35002", LotSize: 75},
+	)
+	tests := []struct {
+		name       string
+		securityId string
+		netQty     int32
+		master     *InstrumentMaster
+		want       string
+	}{
+		{"within the freeze quantity", "35001", 1800, master, URIPlaceOrder},
+		{"short within the freeze quantity", "35001", -750, master, URIPlaceOrder},
+		{"above the freeze quantity", "35001", 1875, master, URISliceOrder},
+		{"unknown freeze quantity", "35002", 75, master, URISliceOrder},
+		{"not in the master", "35003", 75, master, URISliceOrder},
+		{"without a master", "35001", 75, nil, URISliceOrder},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			position, _ := json.Marshal([]Position{{
+				SecurityId:      test.securityId,
+				ExchangeSegment: ExchangeSegmentFNONSE,
+				ProductType:     ProductTypeMargin,
+				NetQty:          test.netQty,
+			}})
+			broker := &exitBroker{positions: string(position)}
+			client := newExitTest(t, broker)
+
+			results, err := client.ExitPositions(context.Background(), ExitRequest{Instruments: test.master})
+			if err != nil || ExitErr(results) != nil {
+				t.Fatalf("ExitPositions: %v, %v", err, ExitErr(results))
+			}
+			if last := broker.calls[len(broker.calls)-1]; last != "POST "+test.want {
+				t.Errorf("exit sent with %s, want %s", last, test.want)
+			}
+
+			order := broker.placed[0]
+			wantSide, wantQty := TransactionTypeSell, test.netQty
+			if test.netQty < 0 {
+				wantSide, wantQty = TransactionTypeBuy, -test.netQty
+			}
+			if order.TransactionType != wantSide || order.Quantity != wantQty || order.OrderType != OrderTypeMarket {
+				t.Errorf("exit order is %s %d %s, want %s %d MARKET", order.TransactionType, order.Quantity, order.OrderType, wantSide, wantQty)
+			}
+			if slices.Contains(broker.calls, "GET "+URIGetOrders) {
+				t.Error("order book fetched without bracket or cover positions")
+			}
+		})
+	}
+}
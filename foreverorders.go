@@ -0,0 +1,245 @@
+package dhanhq
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// ForeverTrigger is a trigger condition of a forever order, an order for
+// Quantity at Price is placed once the last traded price reaches TriggerPrice
+type ForeverTrigger struct {
+	TriggerPrice float64
+	Price        float64
+	Quantity     int32
+}
+
+// ForeverOrderRequest creates a forever order. With only Trigger set it is a
+// SINGLE order, setting StopLoss makes it an OCO order where Trigger is the
+// target and whichever triggers first cancels the other.
+type ForeverOrderRequest struct {
+	DhanClientId      string
+	CorrelationId     string
+	TransactionType   string
+	ExchangeSegment   string
+	ProductType       string
+	OrderType         string
+	Validity          string
+	SecurityId        string
+	DisclosedQuantity int32
+	Trigger           ForeverTrigger
+	StopLoss          *ForeverTrigger
+}
+
+// OrderFlag returns OrderFlagOCO if the request has a stop loss and OrderFlagSingle otherwise
+func (r ForeverOrderRequest) OrderFlag() string {
+	if r.StopLoss != nil {
+		return OrderFlagOCO
+	}
+	return OrderFlagSingle
+}
+
+// MarshalJSON flattens the trigger conditions into the fields DhanHQ expects
+func (r ForeverOrderRequest) MarshalJSON() ([]byte, error) {
+	body := struct {
+		DhanClientId      string  `json:"dhanClientId"`
+		CorrelationId     string  `json:"correlationId,omitempty"`
+		OrderFlag         string  `json:"orderFlag"`
+		TransactionType   string  `json:"transactionType"`
+		ExchangeSegment   string  `json:"exchangeSegment"`
+		ProductType       string  `json:"productType"`
+		OrderType         string  `json:"orderType"`
+		Validity          string  `json:"validity"`
+		SecurityId        string  `json:"securityId"`
+		Quantity          int32   `json:"quantity"`
+		DisclosedQuantity int32   `json:"disclosedQuantity,omitempty"`
+		Price             float64 `json:"price"`
+		TriggerPrice      float64 `json:"triggerPrice"`
+		Price1            float64 `json:"price1,omitempty"`
+		TriggerPrice1     float64 `json:"triggerPrice1,omitempty"`
+		Quantity1         int32   `json:"quantity1,omitempty"`
+	}{
+		DhanClientId:      r.DhanClientId,
+		CorrelationId:     r.CorrelationId,
+		OrderFlag:         r.OrderFlag(),
+		TransactionType:   r.TransactionType,
+		ExchangeSegment:   r.ExchangeSegment,
+		ProductType:       r.ProductType,
+		OrderType:         r.OrderType,
+		Validity:          r.Validity,
+		SecurityId:        r.SecurityId,
+		Quantity:          r.Trigger.Quantity,
+		DisclosedQuantity: r.DisclosedQuantity,
+		Price:             r.Trigger.Price,
+		TriggerPrice:      r.Trigger.TriggerPrice,
+	}
+	if r.StopLoss != nil {
+		body.Price1 = r.StopLoss.Price
+		body.TriggerPrice1 = r.StopLoss.TriggerPrice
+		body.Quantity1 = r.StopLoss.Quantity
+	}
+	return json.Marshal(body)
+}
+
+// ModifyForeverOrderRequest modifies one leg of a forever order, LegName is
+// LegNameTarget for a SINGLE order or the target of an OCO order and
+// LegNameStopLoss for the stop loss of an OCO order
+type ModifyForeverOrderRequest struct {
+	DhanClientId      string  `json:"dhanClientId"`
+	OrderId           string  `json:"orderId"`
+	OrderFlag         string  `json:"orderFlag"`
+	OrderType         string  `json:"orderType"`
+	LegName           string  `json:"legName"`
+	Quantity          int32   `json:"quantity"`
+	Price             float64 `json:"price"`
+	DisclosedQuantity int32   `json:"disclosedQuantity,omitempty"`
+	TriggerPrice      float64 `json:"triggerPrice"`
+	Validity          string  `json:"validity"`
+}
+
+// ForeverOrder is one leg of a forever order as listed by GetForeverOrders,
+// an OCO order is listed once per leg
+type ForeverOrder struct {
+	DhanClientId    string  `json:"dhanClientId"`
+	OrderId         string  `json:"orderId"`
+	OrderStatus     string  `json:"orderStatus"`
+	TransactionType string  `json:"transactionType"`
+	Exchange        string  `json:"exchange"`
+	Segment         string  `json:"segment"`
+	Instrument      string  `json:"instrument"`
+	SecurityId      string  `json:"securityId"`
+	TradingSymbol   string  `json:"tradingSymbol"`
+	OrderType       string  `json:"orderType"`
+	Quantity        int32   `json:"quantity"`
+	Price           float64 `json:"price"`
+	TriggerPrice    float64 `json:"triggerPrice"`
+	LegName         string  `json:"legName"`
+	CreateTime      string  `json:"createTime"`
+	UpdateTime      string  `json:"updateTime"`
+	ExchangeTime    string  `json:"exchangeTime"`
+	DrvExpiryDate   string  `json:"drvExpiryDate"`
+	DrvOptionType   string  `json:"drvOptionType"`
+	DrvStrikePrice  float64 `json:"drvStrikePrice"`
+}
+
+// Trigger returns the trigger condition of the leg
+func (o ForeverOrder) Trigger() ForeverTrigger {
+	return ForeverTrigger{TriggerPrice: o.TriggerPrice, Price: o.Price, Quantity: o.Quantity}
+}
+
+// ProtectHolding returns an OCO forever order selling a CNC holding at the
+// target or the stop loss, whichever triggers first. A zero Quantity in
+// either trigger defaults to the holding's AvailableQty.
+func ProtectHolding(holding Holding, exchangeSegment string, target, stopLoss ForeverTrigger) ForeverOrderRequest {
+	if target.Quantity == 0 {
+		target.Quantity = holding.AvailableQty
+	}
+	if stopLoss.Quantity == 0 {
+		stopLoss.Quantity = holding.AvailableQty
+	}
+	return ForeverOrderRequest{
+		TransactionType: TransactionTypeSell,
+		ExchangeSegment: exchangeSegment,
+		ProductType:     ProductTypeCNC,
+		OrderType:       OrderTypeLimit,
+		Validity:        ValidityDay,
+		SecurityId:      holding.SecurityId,
+		Trigger:         target,
+		StopLoss:        &stopLoss,
+	}
+}
+
+// PlaceForeverOrder creates a SINGLE or OCO forever order
+func (c *Client) PlaceForeverOrder(req ForeverOrderRequest) (OrderResponse, error) {
+	return c.PlaceForeverOrderContext(context.Background(), req)
+}
+
+// PlaceForeverOrderContext is PlaceForeverOrder with a context for cancellation and tracing
+func (c *Client) PlaceForeverOrderContext(ctx context.Context, req ForeverOrderRequest) (_ OrderResponse, err error) {
+	ctx, span := c.startSpan(ctx, "PlaceForeverOrder",
+		Attribute{Key: AttributeSecurityIds, Value: []string{req.ExchangeSegment + ":" + req.SecurityId}})
+	defer func() { endSpan(span, err) }()
+
+	cfg, err := c.authorize(ctx)
+	if err != nil {
+		return OrderResponse{}, err
+	}
+	if req.DhanClientId == "" {
+		req.DhanClientId = cfg.dhanClientId
+	}
+	return c.sendOrder(ctx, cfg, http.MethodPost, cfg.baseURI+URIForeverOrders, req)
+}
+
+// ModifyForeverOrder modifies one leg of a forever order
+func (c *Client) ModifyForeverOrder(req ModifyForeverOrderRequest) (OrderResponse, error) {
+	return c.ModifyForeverOrderContext(context.Background(), req)
+}
+
+// ModifyForeverOrderContext is ModifyForeverOrder with a context for cancellation and tracing
+func (c *Client) ModifyForeverOrderContext(ctx context.Context, req ModifyForeverOrderRequest) (_ OrderResponse, err error) {
+	ctx, span := c.startSpan(ctx, "ModifyForeverOrder")
+	defer func() { endSpan(span, err) }()
+
+	cfg, err := c.authorize(ctx)
+	if err != nil {
+		return OrderResponse{}, err
+	}
+	if req.DhanClientId == "" {
+		req.DhanClientId = cfg.dhanClientId
+	}
+	rURL := cfg.baseURI + fmt.Sprintf(URIModifyForeverOrder, url.PathEscape(req.OrderId))
+	return c.sendOrder(ctx, cfg, http.MethodPut, rURL, req)
+}
+
+// CancelForeverOrder cancels a forever order, both legs of an OCO order are cancelled
+func (c *Client) CancelForeverOrder(orderId string) (OrderResponse, error) {
+	return c.CancelForeverOrderContext(context.Background(), orderId)
+}
+
+// CancelForeverOrderContext is CancelForeverOrder with a context for cancellation and tracing
+func (c *Client) CancelForeverOrderContext(ctx context.Context, orderId string) (_ OrderResponse, err error) {
+	ctx, span := c.startSpan(ctx, "CancelForeverOrder")
+	defer func() { endSpan(span, err) }()
+
+	cfg, err := c.authorize(ctx)
+	if err != nil {
+		return OrderResponse{}, err
+	}
+	rURL := cfg.baseURI + fmt.Sprintf(URICancelForeverOrder, url.PathEscape(orderId))
+	return c.sendOrder(ctx, cfg, http.MethodDelete, rURL, nil)
+}
+
+// GetForeverOrders retrieves all existing forever orders
+func (c *Client) GetForeverOrders() ([]ForeverOrder, error) {
+	return c.GetForeverOrdersContext(context.Background())
+}
+
+// GetForeverOrdersContext is GetForeverOrders with a context for cancellation and tracing
+func (c *Client) GetForeverOrdersContext(ctx context.Context) (_ []ForeverOrder, err error) {
+	ctx, span := c.startSpan(ctx, "GetForeverOrders")
+	defer func() { endSpan(span, err) }()
+
+	cfg, err := c.authorize(ctx)
+	if err != nil {
+		return nil, err
+	}
+	headers := http.Header{
+		"Accept":       {"application/json"},
+		"access-token": {cfg.accessToken},
+	}
+	resp, err := cfg.httpClient.DoContext(ctx, http.MethodGet, cfg.baseURI+URIGetForeverOrders, headers, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkResponse(resp); err != nil {
+		return nil, err
+	}
+
+	var foreverOrders []ForeverOrder
+	if err := json.Unmarshal(resp.Body, &foreverOrders); err != nil {
+		return nil, err
+	}
+	return foreverOrders, nil
+}
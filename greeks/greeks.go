@@ -0,0 +1,161 @@
+// Package greeks prices European options and computes their greeks and
+// implied volatility with the Black-Scholes and Black-76 models. It works
+// offline and does not depend on the DhanHQ client.
+package greeks
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// OptionType is a call or a put
+type OptionType string
+
+const (
+	Call OptionType = "CALL"
+	Put  OptionType = "PUT"
+)
+
+// ParseOptionType accepts the option types of positions (CALL, PUT) and of
+// the instrument master (CE, PE)
+func ParseOptionType(s string) (OptionType, error) {
+	switch strings.ToUpper(strings.TrimSpace(s)) {
+	case "CALL", "CE", "C":
+		return Call, nil
+	case "PUT", "PE", "P":
+		return Put, nil
+	}
+	return "", fmt.Errorf("greeks: unknown option type %q", s)
+}
+
+// Model is the pricing model
+type Model int
+
+const (
+	// BlackScholes prices options on a spot price with a dividend yield
+	BlackScholes Model = iota
+
+	// Black76 prices options on a futures price
+	Black76
+)
+
+func (m Model) String() string {
+	switch m {
+	case BlackScholes:
+		return "Black-Scholes"
+	case Black76:
+		return "Black-76"
+	}
+	return fmt.Sprintf("Model(%d)", int(m))
+}
+
+// Params describes an option and its market
+type Params struct {
+	Type  OptionType
+	Model Model
+
+	// Underlying is the spot price with BlackScholes and the futures price with Black76
+	Underlying float64
+	Strike     float64
+
+	// Expiry is the time to expiry in years, see YearFraction
+	Expiry float64
+
+	// Volatility is annualized, 0.15 for 15%
+	Volatility float64
+
+	// Rate is the continuously compounded risk free rate, 0.07 for 7%
+	Rate float64
+
+	// Dividend is the continuous dividend yield, it is ignored by Black76
+	Dividend float64
+}
+
+// Greeks are the price and sensitivities of one option. Delta and Gamma are
+// per unit of the underlying, Theta is per calendar day, Vega per volatility
+// point (1%) and Rho per rate point (1%).
+type Greeks struct {
+	Price float64
+	Delta float64
+	Gamma float64
+	Theta float64
+	Vega  float64
+	Rho   float64
+}
+
+// DaysPerYear is the day count of YearFraction and of Theta
+const DaysPerYear = 365
+
+// YearFraction returns the time from now to expiry in years, zero once expired
+func YearFraction(now, expiry time.Time) float64 {
+	if !expiry.After(now) {
+		return 0
+	}
+	return expiry.Sub(now).Hours() / 24 / DaysPerYear
+}
+
+// Price returns the price of the option
+func Price(p Params) float64 {
+	return Compute(p).Price
+}
+
+// Compute returns the price and greeks of the option. At or after expiry, or
+// with no volatility, the option is worth its discounted intrinsic value.
+func Compute(p Params) Greeks {
+	sign := 1.0
+	if p.Type == Put {
+		sign = -1
+	}
+	t := math.Max(p.Expiry, 0)
+	q := p.Dividend
+	if p.Model == Black76 {
+		// Black-76 is Black-Scholes with a dividend yield equal to the rate
+		q = p.Rate
+	}
+	underlyingDiscount := math.Exp(-q * t)
+	strikeDiscount := math.Exp(-p.Rate * t)
+
+	if t == 0 || p.Volatility <= 0 || p.Underlying <= 0 || p.Strike <= 0 {
+		var g Greeks
+		forward := p.Underlying * underlyingDiscount
+		strike := p.Strike * strikeDiscount
+		if intrinsic := sign * (forward - strike); intrinsic > 0 {
+			g.Price = intrinsic
+			g.Delta = sign * underlyingDiscount
+		}
+		return g
+	}
+
+	sqrtT := math.Sqrt(t)
+	d1 := (math.Log(p.Underlying/p.Strike) + (p.Rate-q+p.Volatility*p.Volatility/2)*t) / (p.Volatility * sqrtT)
+	d2 := d1 - p.Volatility*sqrtT
+	nd1 := normPDF(d1)
+	spot := p.Underlying * underlyingDiscount
+	strike := p.Strike * strikeDiscount
+
+	var g Greeks
+	g.Price = sign * (spot*normCDF(sign*d1) - strike*normCDF(sign*d2))
+	g.Delta = sign * underlyingDiscount * normCDF(sign*d1)
+	g.Gamma = underlyingDiscount * nd1 / (p.Underlying * p.Volatility * sqrtT)
+	g.Vega = spot * nd1 * sqrtT / 100
+	theta := -spot*nd1*p.Volatility/(2*sqrtT) -
+		sign*p.Rate*strike*normCDF(sign*d2) +
+		sign*q*spot*normCDF(sign*d1)
+	g.Theta = theta / DaysPerYear
+	if p.Model == Black76 {
+		g.Rho = -t * g.Price / 100
+	} else {
+		g.Rho = sign * strike * t * normCDF(sign*d2) / 100
+	}
+	return g
+}
+
+func normCDF(x float64) float64 {
+	return 0.5 * math.Erfc(-x/math.Sqrt2)
+}
+
+func normPDF(x float64) float64 {
+	return math.Exp(-x*x/2) / math.Sqrt(2*math.Pi)
+}
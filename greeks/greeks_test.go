@@ -0,0 +1,172 @@
+package greeks
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// Getters of the greeks checked against reference values
+var (
+	price = func(g Greeks) float64 { return g.Price }
+	delta = func(g Greeks) float64 { return g.Delta }
+	gamma = func(g Greeks) float64 { return g.Gamma }
+	theta = func(g Greeks) float64 { return g.Theta }
+	vega  = func(g Greeks) float64 { return g.Vega }
+	rho   = func(g Greeks) float64 { return g.Rho }
+)
+
+// TestCompute checks published values from Hull, Options, Futures and Other
+// Derivatives, and Haug, The Complete Guide to Option Pricing Formulas.
+// Theta is converted to per day and vega and rho to per 1%.
+func TestCompute(t *testing.T) {
+	hull := Params{Underlying: 49, Strike: 50, Expiry: 20.0 / 52, Volatility: 0.2, Rate: 0.05}
+	tests := []struct {
+		name   string
+		params Params
+		value  func(Greeks) float64
+		want   float64
+		tol    float64
+	}{
+		// Black-Scholes
+		{"Hull call price", Params{Type: Call, Underlying: 42, Strike: 40, Expiry: 0.5, Volatility: 0.2, Rate: 0.1}, price, 4.76, 0.005},
+		{"Hull put price", Params{Type: Put, Underlying: 42, Strike: 40, Expiry: 0.5, Volatility: 0.2, Rate: 0.1}, price, 0.81, 0.005},
+		{"Hull call price 20 weeks", with(hull, Call), price, 2.40, 0.005},
+		{"Hull call delta", with(hull, Call), delta, 0.522, 0.0005},
+		{"Hull call gamma", with(hull, Call), gamma, 0.066, 0.0005},
+		{"Hull call theta", with(hull, Call), theta, -4.31 / DaysPerYear, 0.005 / DaysPerYear},
+		{"Hull call vega", with(hull, Call), vega, 12.1 / 100, 0.05 / 100},
+		{"Hull call rho", with(hull, Call), rho, 8.91 / 100, 0.005 / 100},
+		{"Haug Merton put price", Params{Type: Put, Underlying: 100, Strike: 95, Expiry: 0.5, Volatility: 0.2, Rate: 0.1, Dividend: 0.05}, price, 2.4648, 0.00005},
+		{"Haug call gamma", Params{Type: Call, Underlying: 55, Strike: 60, Expiry: 0.75, Volatility: 0.3, Rate: 0.1}, gamma, 0.0278, 0.00005},
+		{"Haug call vega", Params{Type: Call, Underlying: 55, Strike: 60, Expiry: 0.75, Volatility: 0.3, Rate: 0.1}, vega, 18.9358 / 100, 0.00005 / 100},
+		{"Haug put theta", Params{Type: Put, Underlying: 430, Strike: 405, Expiry: 0.0833, Volatility: 0.2, Rate: 0.07, Dividend: 0.05}, theta, -31.1924 / DaysPerYear, 0.00005 / DaysPerYear},
+		{"Haug call rho", Params{Type: Call, Underlying: 72, Strike: 75, Expiry: 1, Volatility: 0.19, Rate: 0.09}, rho, 38.7325 / 100, 0.00005 / 100},
+
+		// Black-76
+		{"Hull futures put price", Params{Type: Put, Model: Black76, Underlying: 20, Strike: 20, Expiry: 4.0 / 12, Volatility: 0.25, Rate: 0.09}, price, 1.12, 0.005},
+		{"Haug futures call price", Params{Type: Call, Model: Black76, Underlying: 19, Strike: 19, Expiry: 0.75, Volatility: 0.28, Rate: 0.1}, price, 1.7011, 0.00005},
+		{"Haug futures put price", Params{Type: Put, Model: Black76, Underlying: 19, Strike: 19, Expiry: 0.75, Volatility: 0.28, Rate: 0.1}, price, 1.7011, 0.00005},
+		{"Haug futures call delta", Params{Type: Call, Model: Black76, Underlying: 105, Strike: 100, Expiry: 0.5, Volatility: 0.36, Rate: 0.1}, delta, 0.5946, 0.00005},
+		{"Haug futures put delta", Params{Type: Put, Model: Black76, Underlying: 105, Strike: 100, Expiry: 0.5, Volatility: 0.36, Rate: 0.1}, delta, -0.3566, 0.00005},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.value(Compute(test.params)); math.Abs(got-test.want) > test.tol {
+				t.Errorf("got %.6f, want %.6f ± %g", got, test.want, test.tol)
+			}
+		})
+	}
+}
+
+func TestComputeParity(t *testing.T) {
+	for _, model := range []Model{BlackScholes, Black76} {
+		p := Params{Model: model, Underlying: 100, Strike: 105, Expiry: 0.25, Volatility: 0.3, Rate: 0.07, Dividend: 0.02}
+		call, put := Compute(with(p, Call)), Compute(with(p, Put))
+
+		q := p.Dividend
+		if model == Black76 {
+			q = p.Rate
+		}
+		want := p.Underlying*math.Exp(-q*p.Expiry) - p.Strike*math.Exp(-p.Rate*p.Expiry)
+		if got := call.Price - put.Price; math.Abs(got-want) > 1e-9 {
+			t.Errorf("%v: call - put is %.9f, want %.9f", model, got, want)
+		}
+		if got := call.Delta - put.Delta; math.Abs(got-math.Exp(-q*p.Expiry)) > 1e-9 {
+			t.Errorf("%v: call delta - put delta is %.9f, want %.9f", model, got, math.Exp(-q*p.Expiry))
+		}
+		if call.Gamma != put.Gamma || call.Vega != put.Vega {
+			t.Errorf("%v: call and put gamma or vega differ: %+v, %+v", model, call, put)
+		}
+	}
+}
+
+func TestComputeAtExpiry(t *testing.T) {
+	g := Compute(Params{Type: Call, Underlying: 110, Strike: 100, Volatility: 0.2, Rate: 0.05})
+	if g != (Greeks{Price: 10, Delta: 1}) {
+		t.Errorf("expired in the money call is %+v, want its intrinsic value", g)
+	}
+	g = Compute(Params{Type: Put, Underlying: 110, Strike: 100, Volatility: 0.2, Rate: 0.05})
+	if g != (Greeks{}) {
+		t.Errorf("expired out of the money put is %+v, want zero", g)
+	}
+}
+
+func TestImpliedVolatilityRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		params Params
+	}{
+		{"at the money", Params{Type: Call, Underlying: 100, Strike: 100, Expiry: 0.5, Volatility: 0.2, Rate: 0.065}},
+		{"deep in the money call", Params{Type: Call, Underlying: 100, Strike: 50, Expiry: 1, Volatility: 0.6, Rate: 0.065}},
+		{"deep in the money put", Params{Type: Put, Underlying: 100, Strike: 160, Expiry: 1, Volatility: 0.5, Rate: 0.065}},
+		{"deep out of the money call", Params{Type: Call, Underlying: 100, Strike: 200, Expiry: 0.5, Volatility: 0.4, Rate: 0.065}},
+		{"deep out of the money put", Params{Type: Put, Underlying: 22000, Strike: 19000, Expiry: 30.0 / DaysPerYear, Volatility: 0.25, Rate: 0.065}},
+		{"near expiry", Params{Type: Call, Underlying: 22000, Strike: 22100, Expiry: 1.0 / DaysPerYear, Volatility: 0.15, Rate: 0.065}},
+		{"near expiry put", Params{Type: Put, Underlying: 100, Strike: 101, Expiry: 0.5 / DaysPerYear, Volatility: 0.3, Rate: 0.065}},
+		{"high volatility", Params{Type: Call, Underlying: 100, Strike: 100, Expiry: 0.25, Volatility: 3, Rate: 0.065}},
+		{"Black-76", Params{Type: Put, Model: Black76, Underlying: 22000, Strike: 21500, Expiry: 7.0 / DaysPerYear, Volatility: 0.18, Rate: 0.065}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			optionPrice := Price(test.params)
+			vol, err := ImpliedVolatility(test.params, optionPrice)
+			if err != nil {
+				t.Fatalf("ImpliedVolatility of %.6f: %v", optionPrice, err)
+			}
+			if math.Abs(vol-test.params.Volatility) > 1e-5 {
+				t.Errorf("got volatility %.8f, want %.8f", vol, test.params.Volatility)
+			}
+		})
+	}
+}
+
+func TestImpliedVolatilityAtIntrinsicValue(t *testing.T) {
+	// A deep in the money option trading at its intrinsic value has no time value
+	p := Params{Type: Call, Underlying: 22000, Strike: 15000, Expiry: 0.02, Rate: 0.065}
+	vol, err := ImpliedVolatility(p, Price(p))
+	if err != nil || vol != MinVolatility {
+		t.Errorf("got %v, %v, want MinVolatility", vol, err)
+	}
+}
+
+func TestImpliedVolatilityErrors(t *testing.T) {
+	call := Params{Type: Call, Underlying: 100, Strike: 90, Expiry: 0.5, Rate: 0.065}
+	put := with(call, Put)
+	tests := []struct {
+		name   string
+		params Params
+		price  float64
+		want   error
+	}{
+		{"zero price", call, 0, ErrPriceOutOfBounds},
+		{"negative price", call, -1, ErrPriceOutOfBounds},
+		{"below intrinsic value", call, 10, ErrPriceOutOfBounds},
+		{"call above the underlying", call, 100, ErrPriceOutOfBounds},
+		{"put above the discounted strike", put, 90, ErrPriceOutOfBounds},
+		{"expired", Params{Type: Call, Underlying: 100, Strike: 90}, 10, ErrExpired},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := ImpliedVolatility(test.params, test.price); !errors.Is(err, test.want) {
+				t.Errorf("got %v, want %v", err, test.want)
+			}
+		})
+	}
+}
+
+func TestParseOptionType(t *testing.T) {
+	for s, want := range map[string]OptionType{"CALL": Call, "ce": Call, " PE ": Put, "PUT": Put} {
+		if got, err := ParseOptionType(s); err != nil || got != want {
+			t.Errorf("ParseOptionType(%q) = %v, %v, want %v", s, got, err, want)
+		}
+	}
+	if _, err := ParseOptionType("FUT"); err == nil {
+		t.Error("ParseOptionType(FUT) did not fail")
+	}
+}
+
+func with(p Params, optionType OptionType) Params {
+	p.Type = optionType
+	return p
+}
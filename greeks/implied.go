@@ -0,0 +1,92 @@
+package greeks
+
+import (
+	"errors"
+	"math"
+)
+
+// Bounds of the volatility searched by ImpliedVolatility
+const (
+	MinVolatility = 1e-4
+	MaxVolatility = 10
+)
+
+var (
+	// ErrPriceOutOfBounds is returned by ImpliedVolatility for a price that is
+	// not positive, below the discounted intrinsic value or above the
+	// no-arbitrage upper bound
+	ErrPriceOutOfBounds = errors.New("greeks: price is outside the no-arbitrage bounds")
+
+	// ErrNoConvergence is returned by ImpliedVolatility when the solver does not converge
+	ErrNoConvergence = errors.New("greeks: implied volatility did not converge")
+
+	// ErrExpired is returned by ImpliedVolatility for an option with no time to expiry
+	ErrExpired = errors.New("greeks: option has expired")
+)
+
+// impliedTolerance is the pricing error at which ImpliedVolatility stops
+const impliedTolerance = 1e-8
+
+// ImpliedVolatility returns the volatility at which the option is worth
+// price, p.Volatility is ignored. It takes Newton steps on vega and falls
+// back to bisection whenever a step leaves the bracket of the root, so it
+// also converges for deep in or out of the money options.
+func ImpliedVolatility(p Params, price float64) (float64, error) {
+	if p.Expiry <= 0 {
+		return 0, ErrExpired
+	}
+	p.Volatility = 0
+	lower := Price(p)
+	upper := p.Underlying * math.Exp(-p.Dividend*p.Expiry)
+	if p.Model == Black76 {
+		upper = p.Underlying * math.Exp(-p.Rate*p.Expiry)
+	}
+	if p.Type == Put {
+		upper = p.Strike * math.Exp(-p.Rate*p.Expiry)
+	}
+	if price <= 0 || price < lower-impliedTolerance || price >= upper {
+		return 0, ErrPriceOutOfBounds
+	}
+
+	lo, hi := MinVolatility, float64(MaxVolatility)
+	if price-Price(withVolatility(p, lo)) <= impliedTolerance {
+		return lo, nil
+	}
+	if Price(withVolatility(p, hi)) < price {
+		return 0, ErrPriceOutOfBounds
+	}
+
+	// Brenner-Subrahmanyam approximation as the starting point
+	vol := math.Sqrt(2*math.Pi/p.Expiry) * price / p.Underlying
+	if vol <= lo || vol >= hi || math.IsNaN(vol) {
+		vol = 0.3
+	}
+	for i := 0; i < 100; i++ {
+		g := Compute(withVolatility(p, vol))
+		diff := g.Price - price
+		if math.Abs(diff) < impliedTolerance {
+			return vol, nil
+		}
+		if diff > 0 {
+			hi = vol
+		} else {
+			lo = vol
+		}
+		if hi-lo < 1e-12 {
+			return vol, nil
+		}
+
+		// Vega is per volatility point
+		next := vol - diff/(g.Vega*100)
+		if g.Vega <= 0 || next <= lo || next >= hi || math.IsNaN(next) {
+			next = (lo + hi) / 2
+		}
+		vol = next
+	}
+	return 0, ErrNoConvergence
+}
+
+func withVolatility(p Params, vol float64) Params {
+	p.Volatility = vol
+	return p
+}
@@ -0,0 +1,356 @@
+package dhanhq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultGuardInterval is how often a LossGuard checks the positions by default
+const DefaultGuardInterval = 30 * time.Second
+
+// DefaultGuardFlatTimeout is how long a LossGuard waits by default for the
+// square off to close every position before activating the kill switch
+const DefaultGuardFlatTimeout = time.Minute
+
+// guardFlatPollInterval is how often the positions are fetched while waiting for them to close
+const guardFlatPollInterval = 2 * time.Second
+
+// Actions recorded in the audit log of a LossGuard
+const (
+	GuardActionBreach      = "BREACH"
+	GuardActionCancelOrder = "CANCEL_ORDER"
+	GuardActionSquareOff   = "SQUARE_OFF"
+	GuardActionKillSwitch  = "KILL_SWITCH"
+)
+
+// Profit returns the realized plus unrealized profit of the position
+func (p Position) Profit() float64 {
+	return p.RealizedProfit + p.UnrealizedProfit
+}
+
+// Profit returns the realized plus unrealized profit of all positions,
+// negative when the day is at a loss
+func (p Positions) Profit() float64 {
+	var profit float64
+	for _, position := range p.Positions {
+		profit += position.Profit()
+	}
+	return profit
+}
+
+// LossGuardConfig configures a LossGuard, the guard trips on whichever
+// threshold is breached first and then runs the enabled actions in order:
+// cancel pending orders, square off positions, activate the kill switch
+type LossGuardConfig struct {
+	// MaxLoss is the loss of the day, as a positive amount, at which the guard trips
+	MaxLoss float64
+
+	// MaxDrawdown is the fall of the account equity from its peak of the day,
+	// as a fraction such as 0.05 for 5%, at which the guard trips. The equity
+	// is the start of day limit from GetFundLimit plus the profit of the day.
+	MaxDrawdown float64
+
+	// Interval is how often the positions are checked, DefaultGuardInterval by default
+	Interval time.Duration
+
+	// CancelPendingOrders cancels every pending order when the guard trips,
+	// except the stop loss legs of bracket and cover orders with SquareOff
+	CancelPendingOrders bool
+
+	// SquareOff closes every open position at market when the guard trips,
+	// see ExitPositions
+	SquareOff bool
+
+	// Instruments, if set, provides the freeze quantities for squaring off,
+	// orders not known to be within them are sliced
+	Instruments *InstrumentMaster
+
+	// ActivateKillSwitch activates the kill switch when the guard trips,
+	// DhanHQ only allows it once no position is open and no order pending
+	ActivateKillSwitch bool
+
+	// FlatTimeout is how long the guard waits for the square off to close
+	// every position before activating the kill switch, DefaultGuardFlatTimeout
+	// by default. The kill switch is still attempted after the timeout.
+	FlatTimeout time.Duration
+
+	// OnBreach is called once when the guard trips, with the profit that tripped it
+	OnBreach func(profit float64)
+
+	// OnError is called with the errors of checks and actions, Run keeps going after them
+	OnError func(err error)
+
+	// OnAudit is called with every entry added to the audit log
+	OnAudit func(entry AuditEntry)
+}
+
+// AuditEntry records an action taken by a LossGuard
+type AuditEntry struct {
+	Time       time.Time
+	Action     string
+	OrderId    string
+	SecurityId string
+	Detail     string
+	Err        error
+}
+
+func (e AuditEntry) String() string {
+	s := e.Time.Format(time.RFC3339) + " " + e.Action
+	if e.SecurityId != "" {
+		s += " security " + e.SecurityId
+	}
+	if e.OrderId != "" {
+		s += " order " + e.OrderId
+	}
+	if e.Detail != "" {
+		s += ": " + e.Detail
+	}
+	if e.Err != nil {
+		s += " failed: " + e.Err.Error()
+	}
+	return s
+}
+
+// LossGuard is a risk supervisor that periodically checks the realized plus
+// unrealized profit of the day from GetPositions, and the equity from
+// GetFundLimit if MaxDrawdown is set, and trips once a threshold is breached.
+// Pointing the client at a fake server with WithBaseURI exercises the guard
+// end to end.
+type LossGuard struct {
+	client *Client
+	config LossGuardConfig
+
+	mu         sync.Mutex
+	tripped    bool
+	profit     float64
+	peakEquity float64
+	audit      []AuditEntry
+}
+
+// NewLossGuard creates a loss guard for the client's account, start it with Run
+func (c *Client) NewLossGuard(config LossGuardConfig) *LossGuard {
+	if config.Interval <= 0 {
+		config.Interval = DefaultGuardInterval
+	}
+	if config.FlatTimeout <= 0 {
+		config.FlatTimeout = DefaultGuardFlatTimeout
+	}
+	return &LossGuard{client: c, config: config}
+}
+
+// Tripped reports whether a threshold has been breached
+func (g *LossGuard) Tripped() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.tripped
+}
+
+// Profit returns the profit seen by the last check
+func (g *LossGuard) Profit() float64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.profit
+}
+
+// AuditLog returns the actions taken by the guard so far
+func (g *LossGuard) AuditLog() []AuditEntry {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return append([]AuditEntry(nil), g.audit...)
+}
+
+func (g *LossGuard) record(entry AuditEntry) {
+	entry.Time = time.Now()
+	g.mu.Lock()
+	g.audit = append(g.audit, entry)
+	g.mu.Unlock()
+	if g.config.OnAudit != nil {
+		g.config.OnAudit(entry)
+	}
+}
+
+// Check fetches the positions once, and the fund limit if MaxDrawdown is
+// set, and trips the guard if a threshold is breached. It reports whether
+// the guard is tripped.
+func (g *LossGuard) Check(ctx context.Context) (bool, error) {
+	positions, err := g.client.GetPositionsContext(ctx)
+	if err != nil {
+		return g.Tripped(), err
+	}
+	profit := positions.Profit()
+
+	var equity float64
+	if g.config.MaxDrawdown > 0 {
+		fundLimit, err := g.client.GetFundLimitContext(ctx)
+		if err != nil {
+			return g.Tripped(), err
+		}
+		equity = fundLimit.SodLimit + profit
+	}
+
+	g.mu.Lock()
+	g.profit = profit
+	if equity > g.peakEquity {
+		g.peakEquity = equity
+	}
+	var reason string
+	switch {
+	case g.tripped:
+	case g.config.MaxLoss > 0 && -profit >= g.config.MaxLoss:
+		reason = fmt.Sprintf("loss %.2f reached the limit of %.2f", -profit, g.config.MaxLoss)
+	case g.config.MaxDrawdown > 0 && g.peakEquity > 0 && (g.peakEquity-equity)/g.peakEquity >= g.config.MaxDrawdown:
+		reason = fmt.Sprintf("equity %.2f is %.2f%% below its peak of %.2f",
+			equity, 100*(g.peakEquity-equity)/g.peakEquity, g.peakEquity)
+	}
+	if reason != "" {
+		g.tripped = true
+	}
+	tripped := g.tripped
+	g.mu.Unlock()
+
+	if reason != "" {
+		g.record(AuditEntry{Action: GuardActionBreach, Detail: reason})
+		return tripped, g.trip(ctx, profit, positions)
+	}
+	return tripped, nil
+}
+
+// trip runs the actions for a breach, it carries on after errors and returns them joined
+func (g *LossGuard) trip(ctx context.Context, profit float64, positions Positions) error {
+	if g.config.OnBreach != nil {
+		g.config.OnBreach(profit)
+	}
+
+	var errs []error
+	if g.config.CancelPendingOrders {
+		errs = append(errs, g.cancelPendingOrders(ctx)...)
+	}
+	if g.config.SquareOff {
+		errs = append(errs, g.squareOff(ctx, positions)...)
+	}
+	if g.config.ActivateKillSwitch {
+		if g.config.SquareOff {
+			if err := g.waitFlat(ctx); err != nil {
+				g.record(AuditEntry{Action: GuardActionKillSwitch, Detail: "waiting for the positions to close", Err: err})
+				errs = append(errs, err)
+			}
+		}
+		resp, err := g.client.ActivateKillSwitchContext(ctx)
+		g.record(AuditEntry{Action: GuardActionKillSwitch, Detail: resp.KillSwitchStatus, Err: err})
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+func (g *LossGuard) cancelPendingOrders(ctx context.Context) []error {
+	orders, err := g.client.GetOrdersContext(ctx)
+	if err != nil {
+		g.record(AuditEntry{Action: GuardActionCancelOrder, Detail: "fetching the order book", Err: err})
+		return []error{err}
+	}
+
+	var errs []error
+	for _, order := range orders {
+		if !order.Pending() {
+			continue
+		}
+		// The square off closes bracket and cover orders through their stop loss legs
+		if g.config.SquareOff && isLegProduct(order.ProductType) && order.LegName == LegNameStopLoss {
+			continue
+		}
+		_, err := g.client.CancelOrderContext(ctx, order.OrderId)
+		g.record(AuditEntry{
+			Action:     GuardActionCancelOrder,
+			OrderId:    order.OrderId,
+			SecurityId: order.SecurityId,
+			Detail:     fmt.Sprintf("%s %d %s", order.TransactionType, order.RemainingQuantity, order.TradingSymbol),
+			Err:        err,
+		})
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+func (g *LossGuard) squareOff(ctx context.Context, positions Positions) []error {
+	results, err := g.client.exitPositions(ctx, positions, ExitRequest{Instruments: g.config.Instruments})
+	if err != nil {
+		g.record(AuditEntry{Action: GuardActionSquareOff, Err: err})
+		return []error{err}
+	}
+
+	var errs []error
+	for _, result := range results {
+		orderIds := make([]string, len(result.Orders))
+		for i, order := range result.Orders {
+			orderIds[i] = order.OrderId
+		}
+		g.record(AuditEntry{
+			Action:     GuardActionSquareOff,
+			OrderId:    strings.Join(orderIds, ","),
+			SecurityId: result.Position.SecurityId,
+			Detail:     fmt.Sprintf("%s %d %s at market", result.Order.TransactionType, result.Order.Quantity, result.Position.TradingSymbol),
+			Err:        result.Err,
+		})
+		errs = append(errs, result.Err)
+	}
+	return errs
+}
+
+// waitFlat polls the positions until none is open or FlatTimeout passes
+func (g *LossGuard) waitFlat(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, g.config.FlatTimeout)
+	defer cancel()
+	ticker := time.NewTicker(min(guardFlatPollInterval, g.config.FlatTimeout/4))
+	defer ticker.Stop()
+	for {
+		positions, err := g.client.GetPositionsContext(ctx)
+		if err == nil && openPositions(positions) == 0 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			if err != nil {
+				return fmt.Errorf("dhanhq: positions still open after %v: %w", g.config.FlatTimeout, err)
+			}
+			return fmt.Errorf("dhanhq: %d positions still open after %v", openPositions(positions), g.config.FlatTimeout)
+		case <-ticker.C:
+		}
+	}
+}
+
+// openPositions counts the positions with a net quantity
+func openPositions(positions Positions) int {
+	var open int
+	for _, position := range positions.Positions {
+		if position.NetQty != 0 {
+			open++
+		}
+	}
+	return open
+}
+
+// Run checks the positions every Interval until the guard trips or ctx is
+// done. Once the guard has tripped it returns the errors of the actions,
+// nil if they all succeeded, and ctx.Err() if ctx is done before.
+func (g *LossGuard) Run(ctx context.Context) error {
+	ticker := time.NewTicker(g.config.Interval)
+	defer ticker.Stop()
+	for {
+		tripped, err := g.Check(ctx)
+		if err != nil && g.config.OnError != nil {
+			g.config.OnError(err)
+		}
+		if tripped {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,266 @@
+package dhanhq
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeBroker serves the endpoints used by a LossGuard and records the calls
+type fakeBroker struct {
+	t *testing.T
+
+	mu       sync.Mutex
+	calls    []string
+	netQty   int32
+	profit   float64
+	pending  bool
+	fillsAt  int // square off orders fill on this positions fetch after the order
+	fetches  int
+	killCode int
+
+	sodLimit       float64
+	fundLimitFails bool
+}
+
+func (b *fakeBroker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	call := r.Method + " " + r.URL.Path
+	b.calls = append(b.calls, call)
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == URIPositions:
+		if b.fillsAt > 0 {
+			b.fetches++
+			if b.fetches >= b.fillsAt {
+				b.netQty = 0
+			}
+		}
+		fmt.Fprintf(w, `[{"securityId":"1333","tradingSymbol":"HDFCBANK","exchangeSegment":"NSE_EQ","productType":"INTRADAY","netQty":%d,"unrealizedProfit":%g}]`, b.netQty, b.profit)
+	case r.Method == http.MethodGet && r.URL.Path == URIFundLimit:
+		if b.fundLimitFails {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"errorType":"Internal_Server_Error","errorCode":"DH-908","errorMessage":"Internal server error"}`))
+			return
+		}
+		fmt.Fprintf(w, `{"dhanClientId":"client","sodLimit":%g}`, b.sodLimit)
+	case r.Method == http.MethodGet && r.URL.Path == URIGetOrders:
+		status := OrderStatusTraded
+		if b.pending {
+			status = OrderStatusPending
+		}
+		fmt.Fprintf(w, `[{"orderId":"1","orderStatus":%q,"securityId":"1333"}]`, status)
+	case r.Method == http.MethodDelete && r.URL.Path == "/orders/1":
+		b.pending = false
+		w.Write([]byte(`{"orderId":"1","orderStatus":"CANCELLED"}`))
+	case r.Method == http.MethodPost && (r.URL.Path == URIPlaceOrder || r.URL.Path == URISliceOrder):
+		var order OrderRequest
+		if err := json.NewDecoder(r.Body).Decode(&order); err != nil {
+			b.t.Errorf("decoding order: %v", err)
+		}
+		if order.TransactionType != TransactionTypeSell || order.Quantity != b.netQty {
+			b.t.Errorf("square off order is %s %d, want SELL %d", order.TransactionType, order.Quantity, b.netQty)
+		}
+		if b.fillsAt == 0 {
+			b.netQty = 0
+		}
+		if r.URL.Path == URISliceOrder {
+			w.Write([]byte(`[{"orderId":"2","orderStatus":"TRANSIT"}]`))
+			return
+		}
+		w.Write([]byte(`{"orderId":"2","orderStatus":"TRANSIT"}`))
+	case r.Method == http.MethodPost && r.URL.Path == URIKillSwitch:
+		if b.netQty != 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"errorType":"Order_Error","errorCode":"DH-906","errorMessage":"Positions are open"}`))
+			return
+		}
+		if b.killCode != 0 {
+			w.WriteHeader(b.killCode)
+			w.Write([]byte(`{"errorType":"Order_Error","errorCode":"DH-906","errorMessage":"Kill switch unavailable"}`))
+			return
+		}
+		w.Write([]byte(`{"dhanClientId":"client","killSwitchStatus":"Kill Switch has been successfully activated"}`))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (b *fakeBroker) Calls() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return slices.Clone(b.calls)
+}
+
+func newGuardTest(t *testing.T, broker *fakeBroker, config LossGuardConfig) *LossGuard {
+	t.Helper()
+	broker.t = t
+	srv := httptest.NewServer(broker)
+	t.Cleanup(srv.Close)
+	client := NewClient(WithBaseURI(srv.URL), WithCredentials("client", "token"))
+	return client.NewLossGuard(config)
+}
+
+func TestLossGuardTripSequence(t *testing.T) {
+	broker := &fakeBroker{netQty: 10, profit: -6000, pending: true}
+	var breaches []float64
+	guard := newGuardTest(t, broker, LossGuardConfig{
+		MaxLoss:             5000,
+		Interval:            time.Millisecond,
+		CancelPendingOrders: true,
+		SquareOff:           true,
+		ActivateKillSwitch:  true,
+		OnBreach:            func(profit float64) { breaches = append(breaches, profit) },
+	})
+
+	if err := guard.Run(context.Background()); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if !guard.Tripped() {
+		t.Error("guard did not trip")
+	}
+	if !slices.Equal(breaches, []float64{-6000}) {
+		t.Errorf("OnBreach called with %v, want [-6000]", breaches)
+	}
+
+	want := []string{
+		"GET " + URIPositions,
+		"GET " + URIGetOrders,
+		"DELETE /orders/1",
+		"POST " + URISliceOrder,
+		"GET " + URIPositions,
+		"POST " + URIKillSwitch,
+	}
+	if calls := broker.Calls(); !slices.Equal(calls, want) {
+		t.Errorf("calls are\n%s\nwant\n%s", strings.Join(calls, "\n"), strings.Join(want, "\n"))
+	}
+
+	var actions []string
+	for _, entry := range guard.AuditLog() {
+		if entry.Err != nil {
+			t.Errorf("audit entry failed: %v", entry)
+		}
+		actions = append(actions, entry.Action)
+	}
+	wantActions := []string{GuardActionBreach, GuardActionCancelOrder, GuardActionSquareOff, GuardActionKillSwitch}
+	if !slices.Equal(actions, wantActions) {
+		t.Errorf("audit actions are %v, want %v", actions, wantActions)
+	}
+}
+
+func TestLossGuardWaitsForFlatPositions(t *testing.T) {
+	broker := &fakeBroker{netQty: 10, profit: -6000, fillsAt: 3}
+	guard := newGuardTest(t, broker, LossGuardConfig{
+		MaxLoss:            5000,
+		SquareOff:          true,
+		ActivateKillSwitch: true,
+		FlatTimeout:        time.Second,
+	})
+
+	tripped, err := guard.Check(context.Background())
+	if !tripped || err != nil {
+		t.Fatalf("Check = %v, %v, want true, nil", tripped, err)
+	}
+	// The breach check counts as the first fetch, the square off fills on the third
+	calls := broker.Calls()
+	if n := strings.Count(strings.Join(calls, "\n"), "GET "+URIPositions); n != 3 {
+		t.Errorf("positions fetched %d times, want 3", n)
+	}
+	if last := calls[len(calls)-1]; last != "POST "+URIKillSwitch {
+		t.Errorf("last call is %s, want the kill switch", last)
+	}
+}
+
+func TestLossGuardRunReturnsActionErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		broker *fakeBroker
+		want   string
+	}{
+		{
+			name:   "positions never close",
+			broker: &fakeBroker{netQty: 10, profit: -6000, fillsAt: 1000},
+			want:   "positions still open",
+		},
+		{
+			name:   "kill switch rejected",
+			broker: &fakeBroker{netQty: 10, profit: -6000, killCode: http.StatusBadRequest},
+			want:   "Kill switch unavailable",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var reported []error
+			guard := newGuardTest(t, test.broker, LossGuardConfig{
+				MaxLoss:            5000,
+				Interval:           time.Millisecond,
+				SquareOff:          true,
+				ActivateKillSwitch: true,
+				FlatTimeout:        50 * time.Millisecond,
+				OnError:            func(err error) { reported = append(reported, err) },
+			})
+
+			err := guard.Run(context.Background())
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Fatalf("Run error is %v, want one containing %q", err, test.want)
+			}
+			if len(reported) != 1 || !errors.Is(reported[0], err) {
+				t.Errorf("OnError got %v, want the error returned by Run", reported)
+			}
+
+			log := guard.AuditLog()
+			if last := log[len(log)-1]; last.Action != GuardActionKillSwitch || last.Err == nil {
+				t.Errorf("last audit entry is %v, want a failed kill switch", last)
+			}
+		})
+	}
+}
+
+func TestLossGuardDoesNotTripWithoutLimit(t *testing.T) {
+	broker := &fakeBroker{netQty: 10}
+	guard := newGuardTest(t, broker, LossGuardConfig{SquareOff: true})
+
+	tripped, err := guard.Check(context.Background())
+	if tripped || err != nil {
+		t.Fatalf("Check = %v, %v, want false, nil", tripped, err)
+	}
+	if calls := broker.Calls(); len(calls) != 1 {
+		t.Errorf("calls are %v, want only the positions", calls)
+	}
+}
+
+func TestLossGuardDoesNotTripOnFundLimitError(t *testing.T) {
+	broker := &fakeBroker{netQty: 10, sodLimit: 100000}
+	guard := newGuardTest(t, broker, LossGuardConfig{MaxDrawdown: 0.05, SquareOff: true})
+
+	if tripped, err := guard.Check(context.Background()); tripped || err != nil {
+		t.Fatalf("first Check = %v, %v, want false, nil", tripped, err)
+	}
+
+	// A failed fetch must not be read as a fund limit of zero, a 100% drawdown
+	broker.mu.Lock()
+	broker.fundLimitFails = true
+	broker.mu.Unlock()
+	tripped, err := guard.Check(context.Background())
+	var errResp ErrorResponse
+	if tripped || !errors.As(err, &errResp) || errResp.ErrorCode != "DH-908" {
+		t.Fatalf("Check = %v, %v, want false and the DH-908 error", tripped, err)
+	}
+	if guard.Tripped() {
+		t.Error("guard tripped on a fund limit error")
+	}
+	for _, call := range broker.Calls() {
+		if strings.HasPrefix(call, "POST") || strings.HasPrefix(call, "DELETE") {
+			t.Errorf("guard acted with %s after a fund limit error", call)
+		}
+	}
+}
@@ -2,11 +2,14 @@ package dhanhq
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"net/url"
+	"time"
 )
 
 // HTTPResponse represents the response from an HTTP request
@@ -16,10 +19,14 @@ type HTTPResponse struct {
 }
 
 // httpClient is a client for making HTTP requests to the DhanHQ API
-// TODO: Add custom loggers and timeouts to the httpClient
 type httpClient struct {
-	client *http.Client
-	debug  bool // debug is used to enable/disable debug logging
+	client  *http.Client
+	debug   bool        // debug is used to enable/disable debug logging
+	logger  *log.Logger // logger defaults to the standard logger
+	metrics *Metrics    // metrics is optional and records every request
+	tracer  Tracer      // tracer is optional and creates a span per attempt
+	limiter RateLimiter // limiter is optional and throttles every attempt
+	retry   RetryPolicy // retry is the zero policy (no retries) by default
 }
 
 // rURL stands for the relative URL for the API endpoints
@@ -36,6 +43,15 @@ type HTTPClient interface {
 	// DoJSON is for sending JSON bodies in POST/PUT requests
 	DoJSON(method, rURL string, queryParams url.Values, jsonBody interface{}, headers http.Header, respObj interface{}) (HTTPResponse, error)
 
+	// DoContext is Do with a context for cancellation and tracing
+	DoContext(ctx context.Context, method, rURL string, headers http.Header, params url.Values) (HTTPResponse, error)
+
+	// DoRawContext is DoRaw with a context for cancellation and tracing
+	DoRawContext(ctx context.Context, method, rURL string, reqBody []byte, headers http.Header) (HTTPResponse, error)
+
+	// DoJSONContext is DoJSON with a context for cancellation and tracing
+	DoJSONContext(ctx context.Context, method, rURL string, queryParams url.Values, jsonBody interface{}, headers http.Header, respObj interface{}) (HTTPResponse, error)
+
 	// GetClient returns the HTTP client
 	GetClient() *httpClient
 }
@@ -50,6 +66,11 @@ func NewHTTPClient(h *http.Client, debug bool) HTTPClient {
 // Do sends an HTTP request with the specified method, URL, headers, and parameters
 // parameters are form-data in POST/PUT and query params in GET methods
 func (c *httpClient) Do(method, rURL string, headers http.Header, params url.Values) (HTTPResponse, error) {
+	return c.DoContext(context.Background(), method, rURL, headers, params)
+}
+
+// DoContext sends an HTTP request like Do, bound to ctx
+func (c *httpClient) DoContext(ctx context.Context, method, rURL string, headers http.Header, params url.Values) (HTTPResponse, error) {
 	var body []byte
 	if method == http.MethodPost || method == http.MethodPut || method == http.MethodPatch {
 		if params != nil {
@@ -76,12 +97,92 @@ func (c *httpClient) Do(method, rURL string, headers http.Header, params url.Val
 	}
 
 	// Call DoRaw with the updated stuff
-	return c.DoRaw(method, rURL, body, headers)
+	return c.DoRawContext(ctx, method, rURL, body, headers)
 }
 
 // DoRaw sends an HTTP request with a raw body, typically in JSON format
 func (c *httpClient) DoRaw(method, rURL string, reqBody []byte, headers http.Header) (HTTPResponse, error) {
-	var resp HTTPResponse
+	return c.DoRawContext(context.Background(), method, rURL, reqBody, headers)
+}
+
+// DoRawContext sends an HTTP request like DoRaw, bound to ctx. The request
+// waits for the rate limiter and is retried according to the retry policy,
+// every attempt gets its own span when a Tracer is set.
+func (c *httpClient) DoRawContext(ctx context.Context, method, rURL string, reqBody []byte, headers http.Header) (HTTPResponse, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	for attempt := 0; ; attempt++ {
+		if c.limiter != nil {
+			start := time.Now()
+			err := c.limiter.Wait(ctx)
+			c.metrics.observeLimiterWait(method, rURL, time.Since(start))
+			if err != nil {
+				return HTTPResponse{}, err
+			}
+		}
+		resp, err := c.attempt(ctx, method, rURL, reqBody, headers.Clone())
+		if !c.retry.shouldRetry(method, attempt, resp, err) || ctx.Err() != nil {
+			return resp, checkStatus(resp, err)
+		}
+
+		wait := c.retry.backoff(attempt, resp)
+		if c.debug {
+			c.getLogger().Printf("Retrying %s %s in %s (attempt %d)", method, rURL, wait, attempt+2)
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return resp, checkStatus(resp, err)
+		case <-timer.C:
+		}
+	}
+}
+
+// StatusError is returned for a response with an error status whose body is
+// not a DhanHQ error, such as the HTML page of a gateway. Error bodies in
+// JSON are returned with a nil error for the caller's checkResponse.
+type StatusError struct {
+	StatusCode int
+	Status     string
+	Body       []byte
+}
+
+func (e *StatusError) Error() string {
+	body := bytes.TrimSpace(e.Body)
+	if len(body) > maxStatusErrorBody {
+		body = append(body[:maxStatusErrorBody:maxStatusErrorBody], "..."...)
+	}
+	return fmt.Sprintf("dhanhq: unexpected response %s: %s", e.Status, body)
+}
+
+// maxStatusErrorBody is how much of the body a StatusError message quotes
+const maxStatusErrorBody = 256
+
+// checkStatus returns a StatusError for a response with an error status
+// and a body that is not JSON, and err otherwise
+func checkStatus(resp HTTPResponse, err error) error {
+	if err != nil || resp.Response == nil || (resp.Response.StatusCode >= 200 && resp.Response.StatusCode < 300) {
+		return err
+	}
+	if json.Valid(resp.Body) {
+		return nil
+	}
+	return &StatusError{StatusCode: resp.Response.StatusCode, Status: resp.Response.Status, Body: resp.Body}
+}
+
+// attempt sends a single HTTP request
+func (c *httpClient) attempt(ctx context.Context, method, rURL string, reqBody []byte, headers http.Header) (resp HTTPResponse, err error) {
+	ctx, span := c.getTracer().Start(ctx, "HTTP "+method)
+	span.SetAttributes(
+		Attribute{Key: AttributeHTTPMethod, Value: method},
+		Attribute{Key: AttributeURL, Value: rURL},
+		Attribute{Key: AttributeEndpoint, Value: endpointLabel(rURL)},
+	)
+	defer func() { endSpan(span, err) }()
+
+	logger := c.getLogger()
 
 	var bodyReader io.Reader
 	if len(reqBody) > 0 {
@@ -89,9 +190,9 @@ func (c *httpClient) DoRaw(method, rURL string, reqBody []byte, headers http.Hea
 	}
 
 	if c.debug {
-		log.Println("Request URL:", rURL)
+		logger.Println("Request URL:", rURL)
 	}
-	req, err := http.NewRequest(method, rURL, bodyReader)
+	req, err := http.NewRequestWithContext(ctx, method, rURL, bodyReader)
 
 	if err != nil {
 		return resp, err
@@ -99,10 +200,10 @@ func (c *httpClient) DoRaw(method, rURL string, reqBody []byte, headers http.Hea
 	// Set headers if provided
 	if headers != nil {
 		if c.debug {
-			log.Println("Request Headers:")
+			logger.Println("Request Headers:")
 			for key, values := range headers {
 				for _, value := range values {
-					log.Printf("%s: %s\n", key, value)
+					logger.Printf("%s: %s\n", key, value)
 				}
 			}
 		}
@@ -114,52 +215,66 @@ func (c *httpClient) DoRaw(method, rURL string, reqBody []byte, headers http.Hea
 
 	// Log the request body if debug is enabled
 	if c.debug {
-		log.Println("Request Body:", string(reqBody))
+		logger.Println("Request Body:", string(reqBody))
 	}
 
 	// Log the headers if debug is enabled
 	if c.debug {
-		log.Println("Request Headers:", req.Header)
+		logger.Println("Request Headers:", req.Header)
 	}
 
+	start := time.Now()
 	httpResponse, err := c.client.Do(req)
 	if err != nil {
+		c.metrics.observe(method, rURL, 0, time.Since(start), "")
 		return resp, err
 	}
 	defer func(Body io.ReadCloser) {
-		err = Body.Close()
-		if err != nil {
+		if err := Body.Close(); err != nil {
 			return
 		}
 	}(httpResponse.Body)
 
 	data, err := io.ReadAll(httpResponse.Body)
 	if err != nil {
+		c.metrics.observe(method, rURL, httpResponse.StatusCode, time.Since(start), "")
 		return resp, err
 	}
 	resp.Response = httpResponse
 	resp.Body = data
+	span.SetAttributes(Attribute{Key: AttributeHTTPStatus, Value: httpResponse.StatusCode})
 	if c.debug {
-		log.Println("Response Status:", httpResponse.Status)
-		log.Println("Response Body:", string(data))
+		logger.Println("Response Status:", httpResponse.Status)
+		logger.Println("Response Body:", string(data))
 	}
 	// Check if the response status code indicates an error
 	if httpResponse.StatusCode < 200 || httpResponse.StatusCode >= 300 {
-		log.Println("Error Response Status:", httpResponse.Status)
+		logger.Println("Error Response Status:", httpResponse.Status)
+		// Non-JSON error bodies become a StatusError once the retries are
+		// done, failing here would stop retrying gateway errors
 		var errResp ErrorResponse
-		if err := json.Unmarshal(data, &errResp); err != nil {
-			return resp, err
+		_ = json.Unmarshal(data, &errResp)
+		c.metrics.observe(method, rURL, httpResponse.StatusCode, time.Since(start), errResp.ErrorCode)
+		if errResp.ErrorCode != "" {
+			span.SetAttributes(Attribute{Key: AttributeErrorCode, Value: errResp.ErrorCode})
 		}
 		if c.debug {
-			log.Println("Error Response:", errResp)
+			logger.Println("Error Response:", errResp)
 		}
-		return resp, err
+		return resp, nil
 	}
+	c.metrics.observe(method, rURL, httpResponse.StatusCode, time.Since(start), "")
 	return resp, nil
 }
 
-// DoJSON sends an HTTP request with a JSON body, typically in POST/PUT requests
+// DoJSON sends an HTTP request with a JSON body, typically in POST/PUT requests,
+// and decodes a successful response into respObj if it is not nil
 func (c *httpClient) DoJSON(method, rURL string, queryParams url.Values, jsonBody interface{}, headers http.Header, respObj interface{}) (HTTPResponse, error) {
+	return c.DoJSONContext(context.Background(), method, rURL, queryParams, jsonBody, headers, respObj)
+}
+
+// DoJSONContext sends an HTTP request like DoJSON, bound to ctx
+func (c *httpClient) DoJSONContext(ctx context.Context, method, rURL string, queryParams url.Values, jsonBody interface{}, headers http.Header, respObj interface{}) (HTTPResponse, error) {
 	var body []byte
 	var err error
 	if jsonBody != nil {
@@ -183,11 +298,36 @@ func (c *httpClient) DoJSON(method, rURL string, queryParams url.Values, jsonBod
 		rURL = parsedURL.String()
 	}
 
-	// Call DoRaw with the updated stuff
-	return c.DoRaw(method, rURL, body, headers)
+	resp, err := c.DoRawContext(ctx, method, rURL, body, headers)
+	if err != nil {
+		return resp, err
+	}
+	// Decode successful responses into respObj, if given
+	if respObj != nil && resp.Response.StatusCode >= 200 && resp.Response.StatusCode < 300 {
+		if err := json.Unmarshal(resp.Body, respObj); err != nil {
+			return resp, err
+		}
+	}
+	return resp, nil
 }
 
 // GetClient returns the HTTP client instance
 func (c *httpClient) GetClient() *httpClient {
 	return c
 }
+
+// getTracer returns the configured tracer or a no-op one
+func (c *httpClient) getTracer() Tracer {
+	if c.tracer == nil {
+		return noopTracer{}
+	}
+	return c.tracer
+}
+
+// getLogger returns the configured logger or the standard logger
+func (c *httpClient) getLogger() *log.Logger {
+	if c.logger == nil {
+		return log.Default()
+	}
+	return c.logger
+}
@@ -0,0 +1,304 @@
+package dhanhq
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// InstrumentMasterURL is the detailed instrument master published by DhanHQ
+const InstrumentMasterURL = "https://images.dhan.co/api-data/api-scrip-master-detailed.csv"
+
+// Instrument is a tradable security from the instrument master
+type Instrument struct {
+	ExchangeSegment  string
+	SecurityId       string
+	ISIN             string
+	InstrumentType   string
+	TradingSymbol    string
+	UnderlyingSymbol string
+	DisplayName      string
+
+	// UnderlyingSecurityId is the security id of the underlying of a
+	// derivative, it is only in the detailed instrument master
+	UnderlyingSecurityId string
+
+	LotSize     int32
+	TickSize    float64
+	ExpiryDate  time.Time
+	StrikePrice float64
+	OptionType  string
+
+	// FreezeQuantity is the largest quantity the exchange accepts in one
+	// order, zero if unknown. It is read from the FREEZE_QTY column when the
+	// master has one, otherwise set it with InstrumentMaster.SetFreezeQuantity.
+	FreezeQuantity int32
+}
+
+// RoundPrice rounds price to the nearest multiple of the instrument's tick size
+func (i Instrument) RoundPrice(price float64) float64 {
+	return RoundToTick(price, i.TickSize)
+}
+
+// InstrumentMaster indexes instruments by exchange segment and security id
+type InstrumentMaster struct {
+	mu          sync.RWMutex
+	instruments map[string]Instrument
+}
+
+func instrumentKey(exchangeSegment, securityId string) string {
+	return exchangeSegment + ":" + securityId
+}
+
+// NewInstrumentMaster creates an instrument master holding instruments
+func NewInstrumentMaster(instruments ...Instrument) *InstrumentMaster {
+	m := &InstrumentMaster{instruments: make(map[string]Instrument, len(instruments))}
+	for _, instrument := range instruments {
+		m.instruments[instrumentKey(instrument.ExchangeSegment, instrument.SecurityId)] = instrument
+	}
+	return m
+}
+
+// Lookup returns the instrument with the given exchange segment and security id
+func (m *InstrumentMaster) Lookup(exchangeSegment, securityId string) (Instrument, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	instrument, ok := m.instruments[instrumentKey(exchangeSegment, securityId)]
+	return instrument, ok
+}
+
+// Instruments returns every instrument in the master, in no particular order
+func (m *InstrumentMaster) Instruments() []Instrument {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	instruments := make([]Instrument, 0, len(m.instruments))
+	for _, instrument := range m.instruments {
+		instruments = append(instruments, instrument)
+	}
+	return instruments
+}
+
+// Len returns the number of instruments in the master
+func (m *InstrumentMaster) Len() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.instruments)
+}
+
+// SetFreezeQuantity sets the freeze quantity of an instrument, it reports
+// whether the instrument is in the master
+func (m *InstrumentMaster) SetFreezeQuantity(exchangeSegment, securityId string, quantity int32) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := instrumentKey(exchangeSegment, securityId)
+	instrument, ok := m.instruments[key]
+	if ok {
+		instrument.FreezeQuantity = quantity
+		m.instruments[key] = instrument
+	}
+	return ok
+}
+
+// instrumentColumns maps the fields of an Instrument to the columns of the
+// detailed and the compact instrument master
+var instrumentColumns = map[string][]string{
+	"exchange":       {"EXCH_ID", "SEM_EXM_EXCH_ID"},
+	"segment":        {"SEGMENT", "SEM_SEGMENT"},
+	"securityId":     {"SECURITY_ID", "SEM_SMST_SECURITY_ID"},
+	"isin":           {"ISIN"},
+	"instrument":     {"INSTRUMENT", "SEM_INSTRUMENT_NAME"},
+	"tradingSymbol":  {"SYMBOL_NAME", "SEM_TRADING_SYMBOL"},
+	"underlying":     {"UNDERLYING_SYMBOL", "SM_SYMBOL_NAME"},
+	"underlyingId":   {"UNDERLYING_SECURITY_ID"},
+	"displayName":    {"DISPLAY_NAME", "SEM_CUSTOM_SYMBOL"},
+	"lotSize":        {"LOT_SIZE", "SEM_LOT_UNITS"},
+	"tickSize":       {"TICK_SIZE", "SEM_TICK_SIZE"},
+	"expiryDate":     {"SM_EXPIRY_DATE", "SEM_EXPIRY_DATE"},
+	"strikePrice":    {"STRIKE_PRICE", "SEM_STRIKE_PRICE"},
+	"optionType":     {"OPTION_TYPE", "SEM_OPTION_TYPE"},
+	"freezeQuantity": {"FREEZE_QTY", "FREEZE_QUANTITY"},
+}
+
+// exchangeSegments maps the exchange and segment columns of the instrument
+// master to the exchange segment used by the API
+var exchangeSegments = map[string]string{
+	"NSE:E": ExchangeSegmentEquityNSE,
+	"NSE:D": ExchangeSegmentFNONSE,
+	"NSE:C": ExchangeSegmentCurNSE,
+	"NSE:I": ExchangeSegmentIndex,
+	"BSE:E": ExchangeSegmentEquityBSE,
+	"BSE:D": ExchangeSegmentFNOBSE,
+	"BSE:C": ExchangeSegmentCurBSE,
+	"BSE:I": ExchangeSegmentIndex,
+	"MCX:M": ExchangeSegmentMCXCOMM,
+}
+
+// ParseInstrumentMaster reads the detailed or the compact instrument master
+// CSV. DhanHQ lists tick sizes in paise, they are converted to rupees.
+func ParseInstrumentMaster(r io.Reader) (*InstrumentMaster, error) {
+	reader := csv.NewReader(r)
+	reader.ReuseRecord = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("dhanhq: reading instrument master header: %w", err)
+	}
+	columns := make(map[string]int)
+	for field, names := range instrumentColumns {
+		columns[field] = -1
+		for i, column := range header {
+			column = strings.TrimSpace(strings.TrimPrefix(column, "\ufeff"))
+			for _, name := range names {
+				if column == name {
+					columns[field] = i
+				}
+			}
+		}
+	}
+	for _, field := range []string{"exchange", "segment", "securityId"} {
+		if columns[field] < 0 {
+			return nil, fmt.Errorf("dhanhq: instrument master has no %s column", field)
+		}
+	}
+
+	master := NewInstrumentMaster()
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("dhanhq: reading instrument master line %d: %w", line, err)
+		}
+		value := func(field string) string {
+			if i := columns[field]; i >= 0 && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		exchangeSegment, ok := exchangeSegments[value("exchange")+":"+value("segment")]
+		if !ok {
+			continue
+		}
+		instrument := Instrument{
+			ExchangeSegment:      exchangeSegment,
+			SecurityId:           value("securityId"),
+			ISIN:                 value("isin"),
+			InstrumentType:       value("instrument"),
+			TradingSymbol:        value("tradingSymbol"),
+			UnderlyingSymbol:     value("underlying"),
+			DisplayName:          value("displayName"),
+			UnderlyingSecurityId: value("underlyingId"),
+			LotSize:              int32(parseFloat(value("lotSize"))),
+			TickSize:             parseFloat(value("tickSize")) / 100,
+			ExpiryDate:           parseInstrumentDate(value("expiryDate")),
+			StrikePrice:          parseFloat(value("strikePrice")),
+			OptionType:           value("optionType"),
+			FreezeQuantity:       int32(parseFloat(value("freezeQuantity"))),
+		}
+		// The master fills these in with placeholders for instruments that are not options
+		if instrument.StrikePrice < 0 {
+			instrument.StrikePrice = 0
+		}
+		if instrument.OptionType == "XX" {
+			instrument.OptionType = ""
+		}
+		master.instruments[instrumentKey(instrument.ExchangeSegment, instrument.SecurityId)] = instrument
+	}
+	return master, nil
+}
+
+// parseFloat parses a number from the instrument master, zero if it is missing
+func parseFloat(s string) float64 {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0
+	}
+	return f
+}
+
+// instrumentDateLayouts are the layouts of the expiry dates in the instrument
+// master and in positions
+var instrumentDateLayouts = []string{
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04:05.0",
+	"2006-01-02",
+	"02-Jan-2006",
+}
+
+// parseInstrumentDate parses an expiry date in IST, the zero time if there is none
+func parseInstrumentDate(s string) time.Time {
+	for _, layout := range instrumentDateLayouts {
+		if t, err := time.ParseInLocation(layout, s, istLocation); err == nil && t.Year() > 1 {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// DownloadInstrumentMaster downloads and parses the instrument master from InstrumentMasterURL
+func (c *Client) DownloadInstrumentMaster() (*InstrumentMaster, error) {
+	return c.DownloadInstrumentMasterContext(context.Background())
+}
+
+// DownloadInstrumentMasterContext is DownloadInstrumentMaster with a context for cancellation and tracing
+func (c *Client) DownloadInstrumentMasterContext(ctx context.Context) (_ *InstrumentMaster, err error) {
+	ctx, span := c.startSpan(ctx, "DownloadInstrumentMaster")
+	defer func() { endSpan(span, err) }()
+
+	cfg := c.snapshot()
+	resp, err := cfg.httpClient.DoContext(ctx, http.MethodGet, InstrumentMasterURL, http.Header{"Accept": {"text/csv"}}, nil)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("dhanhq: downloading instrument master: %s", resp.Response.Status)
+	}
+	return ParseInstrumentMaster(bytes.NewReader(resp.Body))
+}
+
+// RoundToTick rounds price to the nearest multiple of tickSize,
+// price is returned as is if tickSize is not positive
+func RoundToTick(price, tickSize float64) float64 {
+	return toTick(price, tickSize, math.Round)
+}
+
+// FloorToTick rounds price down to a multiple of tickSize
+func FloorToTick(price, tickSize float64) float64 {
+	return toTick(price, tickSize, math.Floor)
+}
+
+// CeilToTick rounds price up to a multiple of tickSize
+func CeilToTick(price, tickSize float64) float64 {
+	return toTick(price, tickSize, math.Ceil)
+}
+
+// OnTick reports whether price is a multiple of tickSize
+func OnTick(price, tickSize float64) bool {
+	return tickSize <= 0 || RoundToTick(price, tickSize) == roundPrecision(price)
+}
+
+func toTick(price, tickSize float64, round func(float64) float64) float64 {
+	if tickSize <= 0 {
+		return price
+	}
+	// Round away the float error of the division first, so that a price
+	// already on a tick is never moved to the next one
+	return roundPrecision(round(roundPrecision(price/tickSize)) * tickSize)
+}
+
+// roundPrecision drops the float error beyond the precision of any price or tick size
+func roundPrecision(f float64) float64 {
+	return math.Round(f*1e6) / 1e6
+}
@@ -0,0 +1,38 @@
+package dhanhq
+
+import "testing"
+
+func TestTickRounding(t *testing.T) {
+	tests := []struct {
+		price, tickSize    float64
+		round, floor, ceil float64
+		onTick             bool
+	}{
+		{1500.07, 0.05, 1500.05, 1500.05, 1500.10, false},
+		{1500.08, 0.05, 1500.10, 1500.05, 1500.10, false},
+		{1500.05, 0.05, 1500.05, 1500.05, 1500.05, true},
+		{0.15, 0.05, 0.15, 0.15, 0.15, true},
+		{0.3, 0.1, 0.3, 0.3, 0.3, true},
+		{101.2, 0.1, 101.2, 101.2, 101.2, true},
+		{101.25, 0.1, 101.3, 101.2, 101.3, false},
+		{22001.3, 0.05, 22001.3, 22001.3, 22001.3, true},
+		{74.12345, 0.0025, 74.1225, 74.1225, 74.125, false},
+		{2512, 1, 2512, 2512, 2512, true},
+		{2512.5, 1, 2513, 2512, 2513, false},
+		{1500.07, 0, 1500.07, 1500.07, 1500.07, true},
+	}
+	for _, test := range tests {
+		if got := RoundToTick(test.price, test.tickSize); got != test.round {
+			t.Errorf("RoundToTick(%g, %g) = %g, want %g", test.price, test.tickSize, got, test.round)
+		}
+		if got := FloorToTick(test.price, test.tickSize); got != test.floor {
+			t.Errorf("FloorToTick(%g, %g) = %g, want %g", test.price, test.tickSize, got, test.floor)
+		}
+		if got := CeilToTick(test.price, test.tickSize); got != test.ceil {
+			t.Errorf("CeilToTick(%g, %g) = %g, want %g", test.price, test.tickSize, got, test.ceil)
+		}
+		if got := OnTick(test.price, test.tickSize); got != test.onTick {
+			t.Errorf("OnTick(%g, %g) = %v, want %v", test.price, test.tickSize, got, test.onTick)
+		}
+	}
+}
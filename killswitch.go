@@ -0,0 +1,107 @@
+package dhanhq
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+)
+
+// KillSwitchResponse is returned by the kill switch endpoints, KillSwitchStatus
+// is a message when switching and KillSwitchActivate or KillSwitchDeactivate
+// when querying the status
+type KillSwitchResponse struct {
+	DhanClientId     string `json:"dhanClientId"`
+	KillSwitchStatus string `json:"killSwitchStatus"`
+}
+
+// ActivateKillSwitch disables trading on the account for the rest of the day,
+// all positions must be closed and no orders pending
+func (c *Client) ActivateKillSwitch() (KillSwitchResponse, error) {
+	return c.ActivateKillSwitchContext(context.Background())
+}
+
+// ActivateKillSwitchContext is ActivateKillSwitch with a context for cancellation and tracing
+func (c *Client) ActivateKillSwitchContext(ctx context.Context) (KillSwitchResponse, error) {
+	return c.setKillSwitch(ctx, "ActivateKillSwitch", KillSwitchActivate)
+}
+
+// DeactivateKillSwitch enables trading on the account again
+func (c *Client) DeactivateKillSwitch() (KillSwitchResponse, error) {
+	return c.DeactivateKillSwitchContext(context.Background())
+}
+
+// DeactivateKillSwitchContext is DeactivateKillSwitch with a context for cancellation and tracing
+func (c *Client) DeactivateKillSwitchContext(ctx context.Context) (KillSwitchResponse, error) {
+	return c.setKillSwitch(ctx, "DeactivateKillSwitch", KillSwitchDeactivate)
+}
+
+func (c *Client) setKillSwitch(ctx context.Context, name, status string) (_ KillSwitchResponse, err error) {
+	ctx, span := c.startSpan(ctx, name)
+	defer func() { endSpan(span, err) }()
+
+	cfg, err := c.authorize(ctx)
+	if err != nil {
+		return KillSwitchResponse{}, err
+	}
+	headers := http.Header{
+		"Accept":       {"application/json"},
+		"access-token": {cfg.accessToken},
+	}
+	params := url.Values{"killSwitchStatus": {status}}
+	resp, err := cfg.httpClient.DoJSONContext(ctx, http.MethodPost, cfg.baseURI+URIKillSwitch, params, nil, headers, nil)
+	if err != nil {
+		return KillSwitchResponse{}, err
+	}
+	if err := checkResponse(resp); err != nil {
+		return KillSwitchResponse{}, err
+	}
+
+	var killSwitch KillSwitchResponse
+	if err := json.Unmarshal(resp.Body, &killSwitch); err != nil {
+		return KillSwitchResponse{}, err
+	}
+	return killSwitch, nil
+}
+
+// GetKillSwitchStatus retrieves whether the kill switch is active on the account
+func (c *Client) GetKillSwitchStatus() (KillSwitchResponse, error) {
+	return c.GetKillSwitchStatusContext(context.Background())
+}
+
+// GetKillSwitchStatusContext is GetKillSwitchStatus with a context for cancellation and tracing
+func (c *Client) GetKillSwitchStatusContext(ctx context.Context) (_ KillSwitchResponse, err error) {
+	ctx, span := c.startSpan(ctx, "GetKillSwitchStatus")
+	defer func() { endSpan(span, err) }()
+
+	cfg, err := c.authorize(ctx)
+	if err != nil {
+		return KillSwitchResponse{}, err
+	}
+	headers := http.Header{
+		"Accept":       {"application/json"},
+		"access-token": {cfg.accessToken},
+	}
+	resp, err := cfg.httpClient.DoContext(ctx, http.MethodGet, cfg.baseURI+URIKillSwitch, headers, nil)
+	if err != nil {
+		return KillSwitchResponse{}, err
+	}
+	if err := checkResponse(resp); err != nil {
+		return KillSwitchResponse{}, err
+	}
+
+	var killSwitch KillSwitchResponse
+	if err := json.Unmarshal(resp.Body, &killSwitch); err != nil {
+		return KillSwitchResponse{}, err
+	}
+	return killSwitch, nil
+}
+
+// KillSwitchActive reports whether the kill switch is active on the account
+func (c *Client) KillSwitchActive(ctx context.Context) (bool, error) {
+	status, err := c.GetKillSwitchStatusContext(ctx)
+	if err != nil {
+		return false, err
+	}
+	return status.KillSwitchStatus == KillSwitchActivate, nil
+}
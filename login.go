@@ -0,0 +1,234 @@
+package dhanhq
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"runtime"
+	"sync/atomic"
+	"time"
+)
+
+// ErrLoginStateMismatch is wrapped in the error of a login that timed out after
+// redirects not belonging to the login it started reached the callback server
+var ErrLoginStateMismatch = errors.New("dhanhq: login state mismatch")
+
+// loginStateCookie holds the state set by the callback server's start page
+const loginStateCookie = "dhanhq_login_state"
+
+// LoginCallbackConfig configures the local server completing a consent login
+type LoginCallbackConfig struct {
+	// RedirectURL is the redirect URL registered with DhanHQ, such as
+	// "http://127.0.0.1:8080/callback", the server listens on its host and port
+	RedirectURL string
+
+	// Timeout bounds the whole login, it defaults to 5 minutes
+	Timeout time.Duration
+
+	// OpenBrowser opens the login page in the default browser
+	OpenBrowser bool
+
+	// Prompt is called with the URL the user has to visit, for example to
+	// print it. Nothing is printed by default, so Prompt or OpenBrowser
+	// must be set.
+	Prompt func(loginURL string)
+}
+
+// LoginWithCallback completes the partner consent login: it generates a
+// consent, sends the user to the login page, captures the tokenId from the
+// redirect on a local server, consumes it and sets the credentials on the client
+func (c *Client) LoginWithCallback(ctx context.Context, partnerSecret string, cfg LoginCallbackConfig) (ConsumeConsentResponse, error) {
+	consent, err := c.GenerateConsentContext(ctx, partnerSecret)
+	if err != nil {
+		return ConsumeConsentResponse{}, err
+	}
+
+	tokenId, err := WaitForTokenId(ctx, c.GenerateConsentLoginURL(consent.ConsentId), cfg)
+	if err != nil {
+		return ConsumeConsentResponse{}, err
+	}
+
+	resp, err := c.ConsumeConsentContext(ctx, tokenId, partnerSecret)
+	if err != nil {
+		return ConsumeConsentResponse{}, err
+	}
+	return resp, c.SetConsumedConsent(resp)
+}
+
+// AppLoginWithCallback is LoginWithCallback for the individual (API key) consent flow
+func (c *Client) AppLoginWithCallback(ctx context.Context, appId, appSecret string, cfg LoginCallbackConfig) (ConsumeConsentResponse, error) {
+	consent, err := c.GenerateAppConsentContext(ctx, appId, appSecret)
+	if err != nil {
+		return ConsumeConsentResponse{}, err
+	}
+
+	tokenId, err := WaitForTokenId(ctx, c.GenerateAppConsentLoginURL(consent.ConsentAppId), cfg)
+	if err != nil {
+		return ConsumeConsentResponse{}, err
+	}
+
+	resp, err := c.ConsumeAppConsentContext(ctx, tokenId, appId, appSecret)
+	if err != nil {
+		return ConsumeConsentResponse{}, err
+	}
+	return resp, c.SetConsumedConsent(resp)
+}
+
+// WaitForTokenId serves a local start page that redirects to loginURL and
+// returns the tokenId DhanHQ appends to the redirect URL after the login.
+// The start page sets a random state cookie which the redirect must carry,
+// so that only the browser that started the login can complete it. Requests
+// without the cookie or the tokenId are rejected without ending the wait,
+// which only ends with the tokenId or with ctx.
+func WaitForTokenId(ctx context.Context, loginURL string, cfg LoginCallbackConfig) (string, error) {
+	if cfg.Prompt == nil && !cfg.OpenBrowser {
+		return "", errors.New("dhanhq: set Prompt or OpenBrowser to show the login page to the user")
+	}
+	redirect, err := url.Parse(cfg.RedirectURL)
+	if err != nil {
+		return "", fmt.Errorf("dhanhq: invalid redirect url: %w", err)
+	}
+	if redirect.Scheme != "http" {
+		return "", fmt.Errorf("dhanhq: redirect url must be a local http url, got %q", cfg.RedirectURL)
+	}
+	callbackPath := redirect.Path
+	if callbackPath == "" {
+		callbackPath = "/"
+	}
+	startPath := "/dhanhq/login"
+	if callbackPath == startPath {
+		startPath = "/dhanhq/start"
+	}
+
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = 5 * time.Minute
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	state, err := randomState()
+	if err != nil {
+		return "", err
+	}
+
+	host := redirect.Host
+	if redirect.Port() == "" {
+		host = net.JoinHostPort(redirect.Hostname(), "80")
+	}
+	listener, err := net.Listen("tcp", host)
+	if err != nil {
+		return "", fmt.Errorf("dhanhq: listening on redirect url: %w", err)
+	}
+
+	type result struct {
+		tokenId string
+		err     error
+	}
+	results := make(chan result, 1)
+	deliver := func(r result) {
+		select {
+		case results <- r:
+		default:
+		}
+	}
+	// rejected holds the reason the last redirect was turned away, it is
+	// reported if the login times out
+	var rejected atomic.Pointer[error]
+	reject := func(w http.ResponseWriter, message string, status int, err error) {
+		http.Error(w, message, status)
+		rejected.Store(&err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(startPath, func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{
+			Name:     loginStateCookie,
+			Value:    state,
+			Path:     "/",
+			HttpOnly: true,
+			SameSite: http.SameSiteLaxMode,
+		})
+		http.Redirect(w, r, loginURL, http.StatusFound)
+	})
+	mux.HandleFunc(callbackPath, func(w http.ResponseWriter, r *http.Request) {
+		// A callback path of "/" also receives requests such as /favicon.ico
+		if r.URL.Path != callbackPath {
+			http.NotFound(w, r)
+			return
+		}
+		cookie, err := r.Cookie(loginStateCookie)
+		if err != nil || subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(state)) != 1 {
+			reject(w, "This login was not started from this browser.", http.StatusForbidden, ErrLoginStateMismatch)
+			return
+		}
+		tokenId := r.URL.Query().Get("tokenId")
+		if tokenId == "" {
+			tokenId = r.URL.Query().Get("tokenid")
+		}
+		if tokenId == "" {
+			reject(w, "The redirect did not contain a tokenId.", http.StatusBadRequest, errors.New("dhanhq: redirect did not contain a tokenId"))
+			return
+		}
+		fmt.Fprintln(w, "Login complete, you can close this window.")
+		deliver(result{tokenId: tokenId})
+	})
+
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			deliver(result{err: err})
+		}
+	}()
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+
+	// The start page is served on the redirect host so the state cookie is sent back with the redirect
+	startURL := (&url.URL{Scheme: "http", Host: redirect.Host, Path: startPath}).String()
+	if cfg.Prompt != nil {
+		cfg.Prompt(startURL)
+	}
+	if cfg.OpenBrowser {
+		_ = openBrowser(startURL)
+	}
+
+	select {
+	case r := <-results:
+		return r.tokenId, r.err
+	case <-ctx.Done():
+		if err := rejected.Load(); err != nil {
+			return "", fmt.Errorf("dhanhq: waiting for login: %w, last redirect rejected: %w", ctx.Err(), *err)
+		}
+		return "", fmt.Errorf("dhanhq: waiting for login: %w", ctx.Err())
+	}
+}
+
+func randomState() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// openBrowser opens rawURL in the default browser of the platform
+func openBrowser(rawURL string) error {
+	switch runtime.GOOS {
+	case "darwin":
+		return exec.Command("open", rawURL).Start()
+	case "windows":
+		return exec.Command("rundll32", "url.dll,FileProtocolHandler", rawURL).Start()
+	default:
+		return exec.Command("xdg-open", rawURL).Start()
+	}
+}
@@ -1,8 +1,12 @@
 package dhanhq
 
 import (
-	"log"
+	"context"
+	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
+	"sync"
 )
 
 type Margin struct {
@@ -28,19 +32,234 @@ type MarginResponse struct {
 }
 
 func (c *Client) CalculateMargins(margin Margin) (MarginResponse, error) {
+	return c.CalculateMarginsContext(context.Background(), margin)
+}
+
+// CalculateMarginsContext is CalculateMargins with a context for cancellation and tracing
+func (c *Client) CalculateMarginsContext(ctx context.Context, margin Margin) (_ MarginResponse, err error) {
+	ctx, span := c.startSpan(ctx, "CalculateMargins",
+		Attribute{Key: AttributeSecurityIds, Value: []string{margin.ExchangeSegment + ":" + margin.SecurityId}})
+	defer func() { endSpan(span, err) }()
+
+	cfg, err := c.authorize(ctx)
+	if err != nil {
+		return MarginResponse{}, err
+	}
 	headers := http.Header{
 		"Content-Type": {"application/json"},
 		"Accept":       {"application/json"},
-		"access-token": {c.accessToken},
+		"access-token": {cfg.accessToken},
 	}
 	var respObj MarginResponse
-	httpResp, err := c.httpClient.DoJSON(http.MethodPost, c.baseURI+URIMarginCalculator, nil, margin, headers, &respObj)
+	httpResp, err := cfg.httpClient.DoJSONContext(ctx, http.MethodPost, cfg.baseURI+URIMarginCalculator, nil, margin, headers, &respObj)
 	if err != nil {
 		return MarginResponse{}, err
 	}
-	if httpResp.Response.StatusCode != http.StatusOK {
-		log.Printf("Error: %s - %s", httpResp.Response.Status, string(httpResp.Body))
+	if err := checkResponse(httpResp); err != nil {
 		return MarginResponse{}, err
 	}
 	return respObj, nil
 }
+
+// BasketMarginRequest holds the legs of a basket whose margin is calculated
+// together, so that hedged legs get their margin benefit
+type BasketMarginRequest struct {
+	Legs []Margin
+
+	// IncludePositions and IncludeOrders add the account's open positions
+	// and pending orders to the basket
+	IncludePositions bool
+	IncludeOrders    bool
+}
+
+// BasketMarginResponse is the combined margin of a basket
+type BasketMarginResponse struct {
+	TotalMargin     float64
+	SpanMargin      float64
+	ExposureMargin  float64
+	EquityMargin    float64
+	FOMargin        float64
+	CommodityMargin float64
+	HedgeBenefit    float64
+	Currency        string
+
+	// Estimated is set when the margin is the sum of the single leg margins
+	// from EstimateBasketMargin, Legs then holds the margin of each leg
+	Estimated bool
+	Legs      []MarginResponse
+}
+
+// marginAmount decodes a margin amount sent either as a number or as a string
+type marginAmount float64
+
+func (a *marginAmount) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		if s == "" {
+			*a = 0
+			return nil
+		}
+		f, err := strconv.ParseFloat(s, 64)
+		*a = marginAmount(f)
+		return err
+	}
+	var f float64
+	err := json.Unmarshal(data, &f)
+	*a = marginAmount(f)
+	return err
+}
+
+type basketMarginBody struct {
+	DhanClientId    string   `json:"dhanClientId"`
+	IncludePosition bool     `json:"includePosition"`
+	IncludeOrders   bool     `json:"includeOrders"`
+	ScripList       []Margin `json:"scripList"`
+}
+
+type basketMarginResult struct {
+	TotalMargin     marginAmount `json:"total_margin"`
+	SpanMargin      marginAmount `json:"span_margin"`
+	ExposureMargin  marginAmount `json:"exposure_margin"`
+	EquityMargin    marginAmount `json:"equity_margin"`
+	FOMargin        marginAmount `json:"fo_margin"`
+	CommodityMargin marginAmount `json:"commodity_margin"`
+	HedgeBenefit    marginAmount `json:"hedge_benefit"`
+	Currency        string       `json:"currency"`
+}
+
+// basketUnavailableStatuses are the statuses for which CalculateBasketMargin
+// falls back to EstimateBasketMargin
+var basketUnavailableStatuses = map[int]bool{
+	http.StatusNotFound:           true,
+	http.StatusMethodNotAllowed:   true,
+	http.StatusNotImplemented:     true,
+	http.StatusBadGateway:         true,
+	http.StatusServiceUnavailable: true,
+	http.StatusGatewayTimeout:     true,
+}
+
+// basketUnavailable reports whether the basket endpoint answered with one of
+// basketUnavailableStatuses
+func basketUnavailable(resp HTTPResponse, err error) bool {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return basketUnavailableStatuses[statusErr.StatusCode]
+	}
+	return err == nil && resp.Response != nil && basketUnavailableStatuses[resp.Response.StatusCode]
+}
+
+// CalculateBasketMargin calculates the combined margin of a basket of legs,
+// including the hedge benefit. When the basket endpoint is unavailable it
+// falls back to EstimateBasketMargin and sets Estimated.
+func (c *Client) CalculateBasketMargin(req BasketMarginRequest) (BasketMarginResponse, error) {
+	return c.CalculateBasketMarginContext(context.Background(), req)
+}
+
+// CalculateBasketMarginContext is CalculateBasketMargin with a context for cancellation and tracing
+func (c *Client) CalculateBasketMarginContext(ctx context.Context, req BasketMarginRequest) (_ BasketMarginResponse, err error) {
+	ctx, span := c.startSpan(ctx, "CalculateBasketMargin", basketSecurityIdsAttribute(req.Legs))
+	defer func() { endSpan(span, err) }()
+
+	cfg, err := c.authorize(ctx)
+	if err != nil {
+		return BasketMarginResponse{}, err
+	}
+	headers := http.Header{
+		"Content-Type": {"application/json"},
+		"Accept":       {"application/json"},
+		"access-token": {cfg.accessToken},
+	}
+	body := basketMarginBody{
+		DhanClientId:    cfg.dhanClientId,
+		IncludePosition: req.IncludePositions,
+		IncludeOrders:   req.IncludeOrders,
+		ScripList:       make([]Margin, len(req.Legs)),
+	}
+	for i, leg := range req.Legs {
+		if leg.DhanClientId == "" {
+			leg.DhanClientId = cfg.dhanClientId
+		}
+		body.ScripList[i] = leg
+	}
+
+	var result basketMarginResult
+	resp, err := cfg.httpClient.DoJSONContext(ctx, http.MethodPost, cfg.baseURI+URIMarginCalculatorMulti, nil, body, headers, &result)
+	if basketUnavailable(resp, err) {
+		return c.EstimateBasketMarginContext(ctx, req)
+	}
+	if err != nil {
+		return BasketMarginResponse{}, err
+	}
+	if err := checkResponse(resp); err != nil {
+		return BasketMarginResponse{}, err
+	}
+	return BasketMarginResponse{
+		TotalMargin:     float64(result.TotalMargin),
+		SpanMargin:      float64(result.SpanMargin),
+		ExposureMargin:  float64(result.ExposureMargin),
+		EquityMargin:    float64(result.EquityMargin),
+		FOMargin:        float64(result.FOMargin),
+		CommodityMargin: float64(result.CommodityMargin),
+		HedgeBenefit:    float64(result.HedgeBenefit),
+		Currency:        result.Currency,
+	}, nil
+}
+
+// maxEstimateConcurrency is how many single leg margins EstimateBasketMargin fetches at once
+const maxEstimateConcurrency = 4
+
+// EstimateBasketMargin sums the single leg margins of CalculateMargins,
+// fetched concurrently. It has no hedge benefit, so it overstates the
+// margin of hedged baskets, and it cannot include the account's positions
+// or orders.
+func (c *Client) EstimateBasketMargin(req BasketMarginRequest) (BasketMarginResponse, error) {
+	return c.EstimateBasketMarginContext(context.Background(), req)
+}
+
+// EstimateBasketMarginContext is EstimateBasketMargin with a context for cancellation and tracing
+func (c *Client) EstimateBasketMarginContext(ctx context.Context, req BasketMarginRequest) (_ BasketMarginResponse, err error) {
+	ctx, span := c.startSpan(ctx, "EstimateBasketMargin", basketSecurityIdsAttribute(req.Legs))
+	defer func() { endSpan(span, err) }()
+
+	if req.IncludePositions || req.IncludeOrders {
+		return BasketMarginResponse{}, errors.New("dhanhq: an estimated basket margin cannot include positions or orders")
+	}
+
+	dhanClientId := c.snapshot().dhanClientId
+	margins := make([]MarginResponse, len(req.Legs))
+	errs := make([]error, len(req.Legs))
+	sem := make(chan struct{}, maxEstimateConcurrency)
+	var wg sync.WaitGroup
+	for i, leg := range req.Legs {
+		if leg.DhanClientId == "" {
+			leg.DhanClientId = dhanClientId
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, leg Margin) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			margins[i], errs[i] = c.CalculateMarginsContext(ctx, leg)
+		}(i, leg)
+	}
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
+		return BasketMarginResponse{}, err
+	}
+
+	basket := BasketMarginResponse{Estimated: true, Legs: margins}
+	for _, margin := range margins {
+		basket.TotalMargin += margin.TotalMargin
+		basket.SpanMargin += margin.SpanMargin
+		basket.ExposureMargin += margin.ExposureMargin
+	}
+	return basket, nil
+}
+
+func basketSecurityIdsAttribute(legs []Margin) Attribute {
+	ids := make([]string, len(legs))
+	for i, leg := range legs {
+		ids[i] = leg.ExchangeSegment + ":" + leg.SecurityId
+	}
+	return Attribute{Key: AttributeSecurityIds, Value: ids}
+}
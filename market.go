@@ -1,6 +1,7 @@
 package dhanhq
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 )
@@ -90,18 +91,30 @@ type ChartingData struct {
 }
 
 func (c *Client) GetLTP(input MarketDataInput) (LTPResponse, error) {
+	return c.GetLTPContext(context.Background(), input)
+}
+
+// GetLTPContext is GetLTP with a context for cancellation and tracing
+func (c *Client) GetLTPContext(ctx context.Context, input MarketDataInput) (_ LTPResponse, err error) {
+	ctx, span := c.startSpan(ctx, "GetLTP", securityIdsAttribute(input))
+	defer func() { endSpan(span, err) }()
+
+	cfg, err := c.authorize(ctx)
+	if err != nil {
+		return LTPResponse{}, err
+	}
 	headers := http.Header{
 		"Content-Type": {"application/json"},
 		"Accept":       {"application/json"},
-		"access-token": {c.accessToken},
-		"client-id":    {c.GetDhanClientId()},
+		"access-token": {cfg.accessToken},
+		"client-id":    {cfg.dhanClientId},
 	}
-	resp, err := c.httpClient.DoJSON(http.MethodPost, c.baseURI+URIMarketfeedLTP, nil, input, headers, &LTPResponse{})
+	resp, err := cfg.httpClient.DoJSONContext(ctx, http.MethodPost, cfg.baseURI+URIMarketfeedLTP, nil, input, headers, &LTPResponse{})
 	if err != nil {
 		return LTPResponse{}, err
 	}
-	if resp.Response.StatusCode != http.StatusOK {
-		return LTPResponse{}, nil
+	if err := checkResponse(resp); err != nil {
+		return LTPResponse{}, err
 	}
 	var ltpResponse LTPResponse
 	if err := json.Unmarshal(resp.Body, &ltpResponse); err != nil {
@@ -112,18 +125,30 @@ func (c *Client) GetLTP(input MarketDataInput) (LTPResponse, error) {
 }
 
 func (c *Client) GetOHLC(input MarketDataInput) (OHLCResponse, error) {
+	return c.GetOHLCContext(context.Background(), input)
+}
+
+// GetOHLCContext is GetOHLC with a context for cancellation and tracing
+func (c *Client) GetOHLCContext(ctx context.Context, input MarketDataInput) (_ OHLCResponse, err error) {
+	ctx, span := c.startSpan(ctx, "GetOHLC", securityIdsAttribute(input))
+	defer func() { endSpan(span, err) }()
+
+	cfg, err := c.authorize(ctx)
+	if err != nil {
+		return OHLCResponse{}, err
+	}
 	headers := http.Header{
 		"Content-Type": {"application/json"},
 		"Accept":       {"application/json"},
-		"access-token": {c.accessToken},
-		"client-id":    {c.GetDhanClientId()},
+		"access-token": {cfg.accessToken},
+		"client-id":    {cfg.dhanClientId},
 	}
-	resp, err := c.httpClient.DoJSON(http.MethodPost, c.baseURI+URIMarketfeedOHLC, nil, input, headers, &OHLCResponse{})
+	resp, err := cfg.httpClient.DoJSONContext(ctx, http.MethodPost, cfg.baseURI+URIMarketfeedOHLC, nil, input, headers, &OHLCResponse{})
 	if err != nil {
 		return OHLCResponse{}, err
 	}
-	if resp.Response.StatusCode != http.StatusOK {
-		return OHLCResponse{}, nil
+	if err := checkResponse(resp); err != nil {
+		return OHLCResponse{}, err
 	}
 	var ohlcResponse OHLCResponse
 	if err := json.Unmarshal(resp.Body, &ohlcResponse); err != nil {
@@ -134,18 +159,30 @@ func (c *Client) GetOHLC(input MarketDataInput) (OHLCResponse, error) {
 }
 
 func (c *Client) GetMarketDepth(input MarketDataInput) (MarketDepthResponse, error) {
+	return c.GetMarketDepthContext(context.Background(), input)
+}
+
+// GetMarketDepthContext is GetMarketDepth with a context for cancellation and tracing
+func (c *Client) GetMarketDepthContext(ctx context.Context, input MarketDataInput) (_ MarketDepthResponse, err error) {
+	ctx, span := c.startSpan(ctx, "GetMarketDepth", securityIdsAttribute(input))
+	defer func() { endSpan(span, err) }()
+
+	cfg, err := c.authorize(ctx)
+	if err != nil {
+		return MarketDepthResponse{}, err
+	}
 	headers := http.Header{
 		"Content-Type": {"application/json"},
 		"Accept":       {"application/json"},
-		"access-token": {c.accessToken},
-		"client-id":    {c.GetDhanClientId()},
+		"access-token": {cfg.accessToken},
+		"client-id":    {cfg.dhanClientId},
 	}
-	resp, err := c.httpClient.DoJSON(http.MethodPost, c.baseURI+URIMarketfeedQuote, nil, input, headers, &MarketDepthResponse{})
+	resp, err := cfg.httpClient.DoJSONContext(ctx, http.MethodPost, cfg.baseURI+URIMarketfeedQuote, nil, input, headers, &MarketDepthResponse{})
 	if err != nil {
 		return MarketDepthResponse{}, err
 	}
-	if resp.Response.StatusCode != http.StatusOK {
-		return MarketDepthResponse{}, nil
+	if err := checkResponse(resp); err != nil {
+		return MarketDepthResponse{}, err
 	}
 	var marketDepthResponse MarketDepthResponse
 	if err := json.Unmarshal(resp.Body, &marketDepthResponse); err != nil {
@@ -156,12 +193,24 @@ func (c *Client) GetMarketDepth(input MarketDataInput) (MarketDepthResponse, err
 }
 
 func (c *Client) GetHistoricalData(input ChartingDataParams) (ChartingData, error) {
+	return c.GetHistoricalDataContext(context.Background(), input)
+}
+
+// GetHistoricalDataContext is GetHistoricalData with a context for cancellation and tracing
+func (c *Client) GetHistoricalDataContext(ctx context.Context, input ChartingDataParams) (_ ChartingData, err error) {
+	ctx, span := c.startSpan(ctx, "GetHistoricalData", Attribute{Key: AttributeSecurityIds, Value: []string{input.ExchangeSegment + ":" + input.SecurityId}})
+	defer func() { endSpan(span, err) }()
+
+	cfg, err := c.authorize(ctx)
+	if err != nil {
+		return ChartingData{}, err
+	}
 	headers := http.Header{
 		"Content-Type": {"application/json"},
 		"Accept":       {"application/json"},
-		"access-token": {c.accessToken},
+		"access-token": {cfg.accessToken},
 	}
-	resp, err := c.httpClient.DoJSON(http.MethodPost, c.baseURI+URIChartsHistorical, nil, input, headers, &ChartingData{})
+	resp, err := cfg.httpClient.DoJSONContext(ctx, http.MethodPost, cfg.baseURI+URIChartsHistorical, nil, input, headers, &ChartingData{})
 	if err != nil {
 		return ChartingData{}, err
 	}
@@ -177,12 +226,24 @@ func (c *Client) GetHistoricalData(input ChartingDataParams) (ChartingData, erro
 }
 
 func (c *Client) GetIntradayData(input ChartingDataParams) (ChartingData, error) {
+	return c.GetIntradayDataContext(context.Background(), input)
+}
+
+// GetIntradayDataContext is GetIntradayData with a context for cancellation and tracing
+func (c *Client) GetIntradayDataContext(ctx context.Context, input ChartingDataParams) (_ ChartingData, err error) {
+	ctx, span := c.startSpan(ctx, "GetIntradayData", Attribute{Key: AttributeSecurityIds, Value: []string{input.ExchangeSegment + ":" + input.SecurityId}})
+	defer func() { endSpan(span, err) }()
+
+	cfg, err := c.authorize(ctx)
+	if err != nil {
+		return ChartingData{}, err
+	}
 	headers := http.Header{
 		"Content-Type": {"application/json"},
 		"Accept":       {"application/json"},
-		"access-token": {c.accessToken},
+		"access-token": {cfg.accessToken},
 	}
-	resp, err := c.httpClient.DoJSON(http.MethodPost, c.baseURI+URIChartsIntraday, nil, input, headers, &ChartingData{})
+	resp, err := cfg.httpClient.DoJSONContext(ctx, http.MethodPost, cfg.baseURI+URIChartsIntraday, nil, input, headers, &ChartingData{})
 	if err != nil {
 		return ChartingData{}, err
 	}
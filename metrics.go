@@ -0,0 +1,310 @@
+package dhanhq
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultLatencyBuckets are the upper bounds (in seconds) of the latency
+// histogram used when NewMetrics is called without buckets
+var DefaultLatencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Metrics collects request counts, latencies, rate-limit rejections, waits
+// in the local rate limiter and DhanHQ error codes for every API endpoint
+// the SDK calls.
+// Attach it with Client.SetMetrics and expose it with Handler.
+type Metrics struct {
+	mu      sync.Mutex
+	buckets []float64
+
+	requests    map[requestKey]uint64
+	latencies   map[endpointKey]*histogram
+	rateLimited map[endpointKey]uint64
+	apiErrors   map[errorKey]uint64
+
+	limiterWaits       map[endpointKey]uint64
+	limiterWaitSeconds map[endpointKey]float64
+}
+
+type endpointKey struct {
+	method   string
+	endpoint string
+}
+
+type requestKey struct {
+	endpointKey
+	status string
+}
+
+type errorKey struct {
+	endpointKey
+	code string
+}
+
+type histogram struct {
+	counts []uint64 // cumulative counts are computed when writing
+	sum    float64
+	count  uint64
+}
+
+// NewMetrics creates a new metrics collector with the given latency
+// histogram buckets in seconds, DefaultLatencyBuckets are used if none are given
+func NewMetrics(buckets ...float64) *Metrics {
+	if len(buckets) == 0 {
+		buckets = DefaultLatencyBuckets
+	}
+	b := append([]float64(nil), buckets...)
+	sort.Float64s(b)
+
+	return &Metrics{
+		buckets:     b,
+		requests:    make(map[requestKey]uint64),
+		latencies:   make(map[endpointKey]*histogram),
+		rateLimited: make(map[endpointKey]uint64),
+		apiErrors:   make(map[errorKey]uint64),
+
+		limiterWaits:       make(map[endpointKey]uint64),
+		limiterWaitSeconds: make(map[endpointKey]float64),
+	}
+}
+
+// observe records a single HTTP request. status is 0 when the request
+// failed before a response was received and errorCode is the DhanHQ
+// errorCode from the response body, if any.
+func (m *Metrics) observe(method, rURL string, status int, duration time.Duration, errorCode string) {
+	if m == nil {
+		return
+	}
+	ek := endpointKey{method: method, endpoint: endpointLabel(rURL)}
+	statusLabel := "error"
+	if status != 0 {
+		statusLabel = strconv.Itoa(status)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.requests[requestKey{endpointKey: ek, status: statusLabel}]++
+
+	h, ok := m.latencies[ek]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(m.buckets))}
+		m.latencies[ek] = h
+	}
+	seconds := duration.Seconds()
+	for i, upper := range m.buckets {
+		if seconds <= upper {
+			h.counts[i]++
+			break
+		}
+	}
+	h.sum += seconds
+	h.count++
+
+	if status == http.StatusTooManyRequests {
+		m.rateLimited[ek]++
+	}
+	if errorCode != "" {
+		m.apiErrors[errorKey{endpointKey: ek, code: errorCode}]++
+	}
+}
+
+// minLimiterWait is the shortest wait in the rate limiter that is counted,
+// shorter ones are the cost of taking a free token
+const minLimiterWait = time.Millisecond
+
+// observeLimiterWait records a request held back by the local rate limiter
+func (m *Metrics) observeLimiterWait(method, rURL string, wait time.Duration) {
+	if m == nil || wait < minLimiterWait {
+		return
+	}
+	ek := endpointKey{method: method, endpoint: endpointLabel(rURL)}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.limiterWaits[ek]++
+	m.limiterWaitSeconds[ek] += wait.Seconds()
+}
+
+// Handler returns an http.Handler serving the metrics in the
+// Prometheus text exposition format
+func (m *Metrics) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		if err := m.WritePrometheus(w); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
+
+// WritePrometheus writes the metrics in the Prometheus text exposition format
+func (m *Metrics) WritePrometheus(w io.Writer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var sb strings.Builder
+
+	sb.WriteString("# HELP dhanhq_requests_total Total number of DhanHQ API requests.\n")
+	sb.WriteString("# TYPE dhanhq_requests_total counter\n")
+	requestKeys := make([]requestKey, 0, len(m.requests))
+	for k := range m.requests {
+		requestKeys = append(requestKeys, k)
+	}
+	sort.Slice(requestKeys, func(i, j int) bool {
+		if requestKeys[i].endpointKey != requestKeys[j].endpointKey {
+			return lessEndpoint(requestKeys[i].endpointKey, requestKeys[j].endpointKey)
+		}
+		return requestKeys[i].status < requestKeys[j].status
+	})
+	for _, k := range requestKeys {
+		fmt.Fprintf(&sb, "dhanhq_requests_total{%s,status=%q} %d\n", k.labels(), k.status, m.requests[k])
+	}
+
+	sb.WriteString("# HELP dhanhq_request_duration_seconds Latency of DhanHQ API requests.\n")
+	sb.WriteString("# TYPE dhanhq_request_duration_seconds histogram\n")
+	for _, k := range sortedEndpoints(m.latencies) {
+		h := m.latencies[k]
+		var cumulative uint64
+		for i, upper := range m.buckets {
+			cumulative += h.counts[i]
+			fmt.Fprintf(&sb, "dhanhq_request_duration_seconds_bucket{%s,le=%q} %d\n",
+				k.labels(), strconv.FormatFloat(upper, 'g', -1, 64), cumulative)
+		}
+		fmt.Fprintf(&sb, "dhanhq_request_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", k.labels(), h.count)
+		fmt.Fprintf(&sb, "dhanhq_request_duration_seconds_sum{%s} %s\n", k.labels(), strconv.FormatFloat(h.sum, 'g', -1, 64))
+		fmt.Fprintf(&sb, "dhanhq_request_duration_seconds_count{%s} %d\n", k.labels(), h.count)
+	}
+
+	sb.WriteString("# HELP dhanhq_rate_limited_total Total number of requests rejected with HTTP 429.\n")
+	sb.WriteString("# TYPE dhanhq_rate_limited_total counter\n")
+	for _, k := range sortedEndpoints(m.rateLimited) {
+		fmt.Fprintf(&sb, "dhanhq_rate_limited_total{%s} %d\n", k.labels(), m.rateLimited[k])
+	}
+
+	sb.WriteString("# HELP dhanhq_rate_limiter_waits_total Total number of requests held back by the local rate limiter.\n")
+	sb.WriteString("# TYPE dhanhq_rate_limiter_waits_total counter\n")
+	for _, k := range sortedEndpoints(m.limiterWaits) {
+		fmt.Fprintf(&sb, "dhanhq_rate_limiter_waits_total{%s} %d\n", k.labels(), m.limiterWaits[k])
+	}
+
+	sb.WriteString("# HELP dhanhq_rate_limiter_wait_seconds_total Total time requests waited in the local rate limiter.\n")
+	sb.WriteString("# TYPE dhanhq_rate_limiter_wait_seconds_total counter\n")
+	for _, k := range sortedEndpoints(m.limiterWaitSeconds) {
+		fmt.Fprintf(&sb, "dhanhq_rate_limiter_wait_seconds_total{%s} %s\n", k.labels(), strconv.FormatFloat(m.limiterWaitSeconds[k], 'g', -1, 64))
+	}
+
+	sb.WriteString("# HELP dhanhq_api_errors_total Total number of DhanHQ error codes returned.\n")
+	sb.WriteString("# TYPE dhanhq_api_errors_total counter\n")
+	errorKeys := make([]errorKey, 0, len(m.apiErrors))
+	for k := range m.apiErrors {
+		errorKeys = append(errorKeys, k)
+	}
+	sort.Slice(errorKeys, func(i, j int) bool {
+		if errorKeys[i].endpointKey != errorKeys[j].endpointKey {
+			return lessEndpoint(errorKeys[i].endpointKey, errorKeys[j].endpointKey)
+		}
+		return errorKeys[i].code < errorKeys[j].code
+	})
+	for _, k := range errorKeys {
+		fmt.Fprintf(&sb, "dhanhq_api_errors_total{%s,code=%q} %d\n", k.labels(), k.code, m.apiErrors[k])
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func (k endpointKey) labels() string {
+	return fmt.Sprintf("endpoint=%q,method=%q", k.endpoint, k.method)
+}
+
+func lessEndpoint(a, b endpointKey) bool {
+	if a.endpoint != b.endpoint {
+		return a.endpoint < b.endpoint
+	}
+	return a.method < b.method
+}
+
+func sortedEndpoints[V any](m map[endpointKey]V) []endpointKey {
+	keys := make([]endpointKey, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return lessEndpoint(keys[i], keys[j]) })
+	return keys
+}
+
+// endpointLabel maps a request URL to the URI constant it was built from,
+// so that "/v2/orders/1234" is reported as "/orders/{id}". URLs that do not
+// match any known endpoint are reported as "other" to bound cardinality.
+func endpointLabel(rURL string) string {
+	path := rURL
+	if i := strings.Index(path, "://"); i >= 0 {
+		path = path[i+3:]
+		if j := strings.Index(path, "/"); j >= 0 {
+			path = path[j:]
+		} else {
+			path = "/"
+		}
+	}
+	if i := strings.IndexAny(path, "?#"); i >= 0 {
+		path = path[:i]
+	}
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+
+	for _, t := range sortedEndpointTemplates {
+		if matchSegments(segments, t.segments) {
+			return t.label
+		}
+	}
+	return "other"
+}
+
+type endpointTemplate struct {
+	label    string
+	segments []string
+}
+
+// sortedEndpointTemplates holds endpointTemplates ordered from the most to the
+// least specific, so that "/orders/slicing" wins over "/orders/%s"
+var sortedEndpointTemplates = func() []endpointTemplate {
+	seen := make(map[string]bool)
+	var templates []endpointTemplate
+	for _, uri := range endpointTemplates {
+		if seen[uri] {
+			continue
+		}
+		seen[uri] = true
+		templates = append(templates, endpointTemplate{
+			label:    strings.ReplaceAll(uri, "%s", "{id}"),
+			segments: strings.Split(strings.Trim(uri, "/"), "/"),
+		})
+	}
+	sort.SliceStable(templates, func(i, j int) bool {
+		if len(templates[i].segments) != len(templates[j].segments) {
+			return len(templates[i].segments) > len(templates[j].segments)
+		}
+		return strings.Count(templates[i].label, "{id}") < strings.Count(templates[j].label, "{id}")
+	})
+	return templates
+}()
+
+// matchSegments reports whether the trailing segments of path match the
+// template, where "%s" in the template matches any single segment
+func matchSegments(path, template []string) bool {
+	if len(path) < len(template) {
+		return false
+	}
+	path = path[len(path)-len(template):]
+	for i, s := range template {
+		if s != "%s" && s != path[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,95 @@
+package dhanhq
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEndpointLabel(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://api.dhan.co/v2/orders", "/orders"},
+		{"https://api.dhan.co/v2/orders/1234", "/orders/{id}"},
+		{"https://api.dhan.co/v2/orders/slicing", "/orders/slicing"},
+		{"https://api.dhan.co/v2/orders/1234?x=1", "/orders/{id}"},
+		{"https://api.dhan.co/v2/super/orders/1234/TARGET_LEG", "/super/orders/{id}/{id}"},
+		{"https://api.dhan.co/v2/trades/2024-01-01/2024-01-31/0", "/trades/{id}/{id}/{id}"},
+		{"https://api.dhan.co/v2/positions/convert", "/positions/convert"},
+		{"https://api.dhan.co/v2/marketfeed/ltp", "/marketfeed/ltp"},
+		{"/killswitch", "/killswitch"},
+		{"https://api.dhan.co/v2/unknown/endpoint/here", "other"},
+		{"https://api.dhan.co", "other"},
+	}
+	for _, test := range tests {
+		if got := endpointLabel(test.url); got != test.want {
+			t.Errorf("endpointLabel(%q) = %q, want %q", test.url, got, test.want)
+		}
+	}
+}
+
+func TestMetricsRecordsRequests(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/orders/1":
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"errorType":"Rate_Limit","errorCode":"DH-904","errorMessage":"Too many requests"}`))
+		default:
+			w.Write([]byte(`[]`))
+		}
+	}))
+	defer srv.Close()
+
+	metrics := NewMetrics()
+	client := NewClient(WithBaseURI(srv.URL), WithCredentials("client", "token"), WithMetrics(metrics),
+		WithRateLimit(20, 1))
+
+	for i := 0; i < 3; i++ {
+		if _, err := client.GetPositions(); err != nil {
+			t.Fatalf("GetPositions: %v", err)
+		}
+	}
+	if _, err := client.GetOrderById("1"); err == nil {
+		t.Fatal("GetOrderById did not fail on a 429")
+	}
+
+	var sb strings.Builder
+	if err := metrics.WritePrometheus(&sb); err != nil {
+		t.Fatal(err)
+	}
+	out := sb.String()
+	for _, want := range []string{
+		`dhanhq_requests_total{endpoint="/positions",method="GET",status="200"} 3`,
+		`dhanhq_requests_total{endpoint="/orders/{id}",method="GET",status="429"} 1`,
+		`dhanhq_request_duration_seconds_count{endpoint="/positions",method="GET"} 3`,
+		`dhanhq_rate_limited_total{endpoint="/orders/{id}",method="GET"} 1`,
+		`dhanhq_api_errors_total{endpoint="/orders/{id}",method="GET",code="DH-904"} 1`,
+		`dhanhq_rate_limiter_waits_total{endpoint="/positions",method="GET"}`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("metrics are missing %s in\n%s", want, out)
+		}
+	}
+}
+
+func TestMetricsLimiterWaitThreshold(t *testing.T) {
+	metrics := NewMetrics()
+	metrics.observeLimiterWait(http.MethodGet, "/positions", time.Microsecond)
+	metrics.observeLimiterWait(http.MethodGet, "/positions", 50*time.Millisecond)
+
+	ek := endpointKey{method: http.MethodGet, endpoint: "/positions"}
+	if got := metrics.limiterWaits[ek]; got != 1 {
+		t.Errorf("counted %d waits, want only the one above minLimiterWait", got)
+	}
+	if got := metrics.limiterWaitSeconds[ek]; got != 0.05 {
+		t.Errorf("wait seconds are %v, want 0.05", got)
+	}
+
+	var nilMetrics *Metrics
+	nilMetrics.observe(http.MethodGet, "/positions", 200, time.Second, "")
+	nilMetrics.observeLimiterWait(http.MethodGet, "/positions", time.Second)
+}
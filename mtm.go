@@ -0,0 +1,219 @@
+package dhanhq
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DefaultLTPPollInterval is how often an LTPPoller calls GetLTP by default,
+// DhanHQ allows one market quote request per second
+const DefaultLTPPollInterval = time.Second
+
+// Tick is the last traded price of a security
+type Tick struct {
+	ExchangeSegment string
+	SecurityId      string
+	LastPrice       float64
+	Time            time.Time
+}
+
+// PriceSource streams the last traded prices of securities, it is
+// implemented by LTPPoller and can be implemented over a live feed
+type PriceSource interface {
+	// Subscribe streams ticks for the securities until ctx is done, when the
+	// channel is closed
+	Subscribe(ctx context.Context, securities MarketDataInput) (<-chan Tick, error)
+}
+
+// LTPPoller is a PriceSource polling GetLTP
+type LTPPoller struct {
+	client   *Client
+	interval time.Duration
+
+	// OnError is called with the errors of GetLTP, polling carries on after them
+	OnError func(err error)
+}
+
+// NewLTPPoller creates a price source polling GetLTP every interval,
+// DefaultLTPPollInterval if interval is not positive
+func (c *Client) NewLTPPoller(interval time.Duration) *LTPPoller {
+	if interval <= 0 {
+		interval = DefaultLTPPollInterval
+	}
+	return &LTPPoller{client: c, interval: interval}
+}
+
+func (p *LTPPoller) Subscribe(ctx context.Context, securities MarketDataInput) (<-chan Tick, error) {
+	if len(securities) == 0 {
+		return nil, errors.New("dhanhq: no securities to subscribe to")
+	}
+	ticks := make(chan Tick, 64)
+	go func() {
+		defer close(ticks)
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+		for {
+			ltp, err := p.client.GetLTPContext(ctx, securities)
+			if err != nil && p.OnError != nil && ctx.Err() == nil {
+				p.OnError(err)
+			}
+			now := time.Now()
+			for segment, quotes := range ltp.Data {
+				for securityId, quote := range quotes {
+					select {
+					case ticks <- Tick{ExchangeSegment: segment, SecurityId: securityId, LastPrice: quote.LastPrice, Time: now}:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return ticks, nil
+}
+
+// PositionMTM is a position marked to the last traded price
+type PositionMTM struct {
+	Position         Position
+	LastPrice        float64
+	UnrealizedProfit float64
+}
+
+// Profit returns the realized plus the marked unrealized profit of the position
+func (p PositionMTM) Profit() float64 {
+	return p.Position.RealizedProfit + p.UnrealizedProfit
+}
+
+// MarkToMarket returns the unrealized profit of a position at lastPrice,
+// from BuyAvg for long and SellAvg for short positions, times the multiplier
+func MarkToMarket(position Position, lastPrice float64) float64 {
+	multiplier := float64(position.Multiplier)
+	if multiplier == 0 {
+		multiplier = 1
+	}
+	switch {
+	case position.NetQty > 0:
+		return (lastPrice - position.BuyAvg) * float64(position.NetQty) * multiplier
+	case position.NetQty < 0:
+		return (position.SellAvg - lastPrice) * float64(-position.NetQty) * multiplier
+	}
+	return 0
+}
+
+// MTMUpdate is the portfolio marked to market after a tick
+type MTMUpdate struct {
+	Time             time.Time
+	Tick             Tick
+	Positions        []PositionMTM
+	RealizedProfit   float64
+	UnrealizedProfit float64
+}
+
+// Profit returns the realized plus unrealized profit of the portfolio
+func (u MTMUpdate) Profit() float64 {
+	return u.RealizedProfit + u.UnrealizedProfit
+}
+
+// MTMEngine recomputes the unrealized profit of positions on every tick
+// of a PriceSource and publishes the result
+type MTMEngine struct {
+	source PriceSource
+
+	mu        sync.Mutex
+	positions []PositionMTM
+	last      MTMUpdate
+}
+
+// NewMTMEngine creates an engine for positions, usually from GetPositions,
+// priced by source. Until a position gets its first tick it keeps the
+// UnrealizedProfit reported by DhanHQ.
+func NewMTMEngine(positions Positions, source PriceSource) *MTMEngine {
+	e := &MTMEngine{source: source}
+	for _, position := range positions.Positions {
+		e.positions = append(e.positions, PositionMTM{
+			Position:         position,
+			UnrealizedProfit: position.UnrealizedProfit,
+		})
+	}
+	e.last = e.update(Tick{Time: time.Now()})
+	return e
+}
+
+// Start subscribes to the prices of the open positions and publishes an
+// update after every tick. Updates are not queued: a slow reader only gets
+// the latest one. The channel is closed when ctx is done or the price
+// source stops.
+func (e *MTMEngine) Start(ctx context.Context) (<-chan MTMUpdate, error) {
+	securities := MarketDataInput{}
+	seen := make(map[string]bool)
+	for _, p := range e.positions {
+		key := instrumentKey(p.Position.ExchangeSegment, p.Position.SecurityId)
+		if p.Position.NetQty == 0 || seen[key] {
+			continue
+		}
+		id, err := strconv.Atoi(p.Position.SecurityId)
+		if err != nil {
+			return nil, err
+		}
+		seen[key] = true
+		securities[p.Position.ExchangeSegment] = append(securities[p.Position.ExchangeSegment], id)
+	}
+	ticks, err := e.source.Subscribe(ctx, securities)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := make(chan MTMUpdate, 1)
+	go func() {
+		defer close(updates)
+		for tick := range ticks {
+			// Ticks without a trade, such as before the open, would mark the positions at zero
+			if tick.LastPrice <= 0 {
+				continue
+			}
+			update := e.update(tick)
+			// Replace an update the reader has not taken yet
+			select {
+			case <-updates:
+			default:
+			}
+			updates <- update
+		}
+	}()
+	return updates, nil
+}
+
+// Snapshot returns the latest update
+func (e *MTMEngine) Snapshot() MTMUpdate {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.last
+}
+
+// update marks the positions of the tick's security and recomputes the totals
+func (e *MTMEngine) update(tick Tick) MTMUpdate {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	update := MTMUpdate{Time: tick.Time, Tick: tick, Positions: make([]PositionMTM, len(e.positions))}
+	for i := range e.positions {
+		p := &e.positions[i]
+		if p.Position.ExchangeSegment == tick.ExchangeSegment && p.Position.SecurityId == tick.SecurityId {
+			p.LastPrice = tick.LastPrice
+			p.UnrealizedProfit = MarkToMarket(p.Position, tick.LastPrice)
+		}
+		update.Positions[i] = *p
+		update.RealizedProfit += p.Position.RealizedProfit
+		update.UnrealizedProfit += p.UnrealizedProfit
+	}
+	e.last = update
+	return update
+}
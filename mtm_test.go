@@ -0,0 +1,77 @@
+package dhanhq
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLTPPollerReportsErrors(t *testing.T) {
+	var polls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if polls.Add(1) == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"errorType":"Rate_Limit","errorCode":"DH-904","errorMessage":"Too many requests"}`))
+			return
+		}
+		w.Write([]byte(`{"data":{"NSE_EQ":{"1333":{"last_price":1650.5}}},"status":"success"}`))
+	}))
+	defer srv.Close()
+
+	client := NewClient(WithBaseURI(srv.URL), WithCredentials("client", "token"))
+	poller := client.NewLTPPoller(time.Millisecond)
+	errs := make(chan error, 10)
+	poller.OnError = func(err error) { errs <- err }
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ticks, err := poller.Subscribe(ctx, MarketDataInput{ExchangeSegmentEquityNSE: {1333}})
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+
+	select {
+	case tick := <-ticks:
+		if tick.SecurityId != "1333" || tick.LastPrice != 1650.5 {
+			t.Errorf("tick is %+v", tick)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no tick after the rate limited poll")
+	}
+
+	select {
+	case err := <-errs:
+		var errResp ErrorResponse
+		if !errors.As(err, &errResp) || errResp.ErrorCode != "DH-904" {
+			t.Errorf("OnError got %v, want the DH-904 error", err)
+		}
+	default:
+		t.Fatal("OnError was not called for the 429")
+	}
+
+	cancel()
+	for range ticks {
+	}
+}
+
+func TestGetLTPReturnsAPIErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"errorType":"Rate_Limit","errorCode":"DH-904","errorMessage":"Too many requests"}`))
+	}))
+	defer srv.Close()
+	client := NewClient(WithBaseURI(srv.URL), WithCredentials("client", "token"))
+	input := MarketDataInput{ExchangeSegmentEquityNSE: {1333}}
+
+	var errResp ErrorResponse
+	if _, err := client.GetLTP(input); !errors.As(err, &errResp) || errResp.ErrorCode != "DH-904" {
+		t.Errorf("GetLTP: got %v, want the DH-904 error", err)
+	}
+	if _, err := client.GetOHLC(input); !errors.As(err, &errResp) || errResp.ErrorCode != "DH-904" {
+		t.Errorf("GetOHLC: got %v, want the DH-904 error", err)
+	}
+}
@@ -0,0 +1,167 @@
+package dhanhq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/tradewithcanvas/godhanhq/greeks"
+)
+
+// Instrument types of derivatives in the instrument master
+const (
+	InstrumentTypeIndexFuture = "FUTIDX"
+	InstrumentTypeIndexOption = "OPTIDX"
+	InstrumentTypeStockFuture = "FUTSTK"
+	InstrumentTypeStockOption = "OPTSTK"
+)
+
+// expiryHour and expiryMinute are the time of day, in IST, at which
+// equity derivatives expire when an expiry date has no time
+const (
+	expiryHour   = 15
+	expiryMinute = 30
+)
+
+// OptionExpiry returns the expiry time of an option or futures position, from
+// the instrument master if it has the position and from DrvExpiryDate
+// otherwise. Dates without a time expire at 15:30 IST.
+func OptionExpiry(position Position, master *InstrumentMaster) (time.Time, error) {
+	var expiry time.Time
+	if master != nil {
+		if instrument, ok := master.Lookup(position.ExchangeSegment, position.SecurityId); ok {
+			expiry = instrument.ExpiryDate
+		}
+	}
+	if expiry.IsZero() {
+		expiry = parseInstrumentDate(position.DrvExpiryDate)
+	}
+	if expiry.IsZero() {
+		return time.Time{}, fmt.Errorf("dhanhq: no expiry date for %s:%s", position.ExchangeSegment, position.SecurityId)
+	}
+	if expiry.Hour() == 0 && expiry.Minute() == 0 {
+		expiry = time.Date(expiry.Year(), expiry.Month(), expiry.Day(), expiryHour, expiryMinute, 0, 0, istLocation)
+	}
+	return expiry, nil
+}
+
+// OptionParams returns the pricing parameters of an option position at now:
+// its type, strike and time to expiry. The caller sets the underlying price,
+// volatility and rate, and switches to greeks.Black76 with a futures price.
+//
+//	params, err := dhanhq.OptionParams(position, master, time.Now())
+//	params.Underlying = prices[underlying]
+//	params.Rate = 0.065
+//	params.Volatility, err = greeks.ImpliedVolatility(params, optionLTP)
+//	g := greeks.Compute(params)
+func OptionParams(position Position, master *InstrumentMaster, now time.Time) (greeks.Params, error) {
+	optionType, strike := position.DrvOptionType, position.DrvStrikePrice
+	if master != nil {
+		if instrument, ok := master.Lookup(position.ExchangeSegment, position.SecurityId); ok && instrument.OptionType != "" {
+			optionType, strike = instrument.OptionType, instrument.StrikePrice
+		}
+	}
+	typ, err := greeks.ParseOptionType(optionType)
+	if err != nil {
+		return greeks.Params{}, fmt.Errorf("dhanhq: %s:%s is not an option: %w", position.ExchangeSegment, position.SecurityId, err)
+	}
+	expiry, err := OptionExpiry(position, master)
+	if err != nil {
+		return greeks.Params{}, err
+	}
+	return greeks.Params{
+		Type:   typ,
+		Model:  greeks.BlackScholes,
+		Strike: strike,
+		Expiry: greeks.YearFraction(now, expiry),
+	}, nil
+}
+
+// Underlying returns the exchange segment and security id of the underlying
+// of a derivative: the index for index derivatives and the cash market
+// stock for stock derivatives. The security id is only known from the
+// detailed instrument master.
+func (i Instrument) Underlying() (exchangeSegment, securityId string, ok bool) {
+	if i.UnderlyingSecurityId == "" {
+		return "", "", false
+	}
+	switch i.InstrumentType {
+	case InstrumentTypeIndexFuture, InstrumentTypeIndexOption:
+		return ExchangeSegmentIndex, i.UnderlyingSecurityId, true
+	case InstrumentTypeStockFuture, InstrumentTypeStockOption:
+		if i.ExchangeSegment == ExchangeSegmentFNOBSE {
+			return ExchangeSegmentEquityBSE, i.UnderlyingSecurityId, true
+		}
+		return ExchangeSegmentEquityNSE, i.UnderlyingSecurityId, true
+	}
+	return "", "", false
+}
+
+// UnderlyingPrices fetches, in one GetLTP request, the last prices of the
+// underlyings of the open derivative positions, keyed by underlying symbol.
+// Positions missing from the master, or whose underlying is unknown, are left out.
+func (c *Client) UnderlyingPrices(positions Positions, master *InstrumentMaster) (map[string]float64, error) {
+	return c.UnderlyingPricesContext(context.Background(), positions, master)
+}
+
+// UnderlyingPricesContext is UnderlyingPrices with a context for cancellation and tracing
+func (c *Client) UnderlyingPricesContext(ctx context.Context, positions Positions, master *InstrumentMaster) (map[string]float64, error) {
+	if master == nil {
+		return nil, errors.New("dhanhq: underlying prices need an instrument master")
+	}
+	securities, symbols := underlyingSecurities(positions, master)
+	if len(securities) == 0 {
+		return make(map[string]float64), nil
+	}
+	ltp, err := c.GetLTPContext(ctx, securities)
+	if err != nil {
+		return nil, err
+	}
+	return underlyingPrices(ltp, symbols), nil
+}
+
+// underlyingSecurities returns the underlyings of the open derivative
+// positions, and their symbols keyed by segment and security id
+func underlyingSecurities(positions Positions, master *InstrumentMaster) (MarketDataInput, map[string]string) {
+	securities := MarketDataInput{}
+	symbols := make(map[string]string)
+	for _, position := range positions.Positions {
+		if position.NetQty == 0 {
+			continue
+		}
+		instrument, ok := master.Lookup(position.ExchangeSegment, position.SecurityId)
+		if !ok {
+			continue
+		}
+		segment, securityId, ok := instrument.Underlying()
+		if !ok {
+			continue
+		}
+		key := instrumentKey(segment, securityId)
+		if _, seen := symbols[key]; seen {
+			continue
+		}
+		id, err := strconv.Atoi(securityId)
+		if err != nil {
+			continue
+		}
+		symbols[key] = instrument.UnderlyingSymbol
+		securities[segment] = append(securities[segment], id)
+	}
+	return securities, symbols
+}
+
+// underlyingPrices returns the last prices of the underlyings in symbols, keyed by symbol
+func underlyingPrices(ltp LTPResponse, symbols map[string]string) map[string]float64 {
+	prices := make(map[string]float64)
+	for segment, quotes := range ltp.Data {
+		for securityId, quote := range quotes {
+			if symbol, ok := symbols[instrumentKey(segment, securityId)]; ok {
+				prices[symbol] = quote.LastPrice
+			}
+		}
+	}
+	return prices
+}
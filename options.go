@@ -0,0 +1,188 @@
+package dhanhq
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+)
+
+// Option configures a Client created with NewClient
+type Option func(o *clientOptions)
+
+// clientOptions collects the options before the Client is built, so that
+// options can be passed in any order
+type clientOptions struct {
+	config      clientConfig
+	httpClient  *http.Client
+	timeout     time.Duration
+	debug       bool
+	logger      *log.Logger
+	metrics     *Metrics
+	tracer      Tracer
+	rateLimiter RateLimiter
+	retry       RetryPolicy
+	tokenPolicy ExpiredTokenPolicy
+	loadAccount string
+}
+
+// NewClient creates a new DhanHQ API client configured by opts.
+// Without options it is equivalent to New(false).
+//
+//	client := dhanhq.NewClient(
+//		dhanhq.WithCredentials(dhanClientId, accessToken),
+//		dhanhq.WithTimeout(10*time.Second),
+//		dhanhq.WithRetryPolicy(dhanhq.DefaultRetryPolicy),
+//	)
+func NewClient(opts ...Option) *Client {
+	o := clientOptions{
+		config: clientConfig{
+			baseURI: baseURI,
+			authURI: authURI,
+		},
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return newClient(o)
+}
+
+// newClient builds a Client from evaluated options, the http.Client,
+// rate limiter and metrics are shared by every client built from o
+func newClient(o clientOptions) *Client {
+	h := &http.Client{}
+	if o.httpClient != nil {
+		// Copy the caller's client so that the timeout does not leak into it
+		copied := *o.httpClient
+		h = &copied
+	}
+	if o.timeout > 0 {
+		h.Timeout = o.timeout
+	}
+
+	cfg := o.config
+	cfg.httpClient = &httpClient{
+		client:  h,
+		debug:   o.debug,
+		logger:  o.logger,
+		metrics: o.metrics,
+		tracer:  o.tracer,
+		limiter: o.rateLimiter,
+		retry:   o.retry,
+	}
+
+	client := &Client{}
+	client.config.Store(&cfg)
+	client.tokens.policy = o.tokenPolicy
+	client.tokens.expiry = cfg.tokenExpiry
+
+	if cfg.tokenStore != nil && o.loadAccount != "" {
+		// A missing token is expected before the first login, anything else is worth knowing about
+		if err := client.LoadCredentials(o.loadAccount); err != nil && !errors.Is(err, ErrTokenNotFound) {
+			cfg.httpClient.GetClient().getLogger().Println("dhanhq: loading credentials:", err)
+		}
+	}
+	return client
+}
+
+// WithAccessToken sets the access token used for API requests
+func WithAccessToken(accessToken string) Option {
+	return func(o *clientOptions) { o.config.accessToken = accessToken }
+}
+
+// WithDhanClientId sets the Dhan client id used for API requests
+func WithDhanClientId(dhanClientId string) Option {
+	return func(o *clientOptions) { o.config.dhanClientId = dhanClientId }
+}
+
+// WithCredentials sets both the Dhan client id and the access token
+func WithCredentials(dhanClientId, accessToken string) Option {
+	return func(o *clientOptions) {
+		o.config.dhanClientId = dhanClientId
+		o.config.accessToken = accessToken
+	}
+}
+
+// WithTokenExpiry sets the expiry time of the access token so that it is
+// tracked by the client's TokenManager
+func WithTokenExpiry(expiry time.Time) Option {
+	return func(o *clientOptions) { o.config.tokenExpiry = expiry }
+}
+
+// WithExpiredTokenPolicy sets what happens to requests attempted with an
+// expired token, requests fail fast with a *TokenExpiredError by default
+func WithExpiredTokenPolicy(policy ExpiredTokenPolicy) Option {
+	return func(o *clientOptions) { o.tokenPolicy = policy }
+}
+
+// WithTokenStore sets the token store and loads the credentials of
+// dhanClientId from it, if present, see Client.SetTokenStore
+func WithTokenStore(store TokenStore, dhanClientId string) Option {
+	return func(o *clientOptions) {
+		o.config.tokenStore = store
+		o.loadAccount = dhanClientId
+	}
+}
+
+// WithPartnerId sets the partner id used for the partner consent flow
+func WithPartnerId(partnerId string) Option {
+	return func(o *clientOptions) { o.config.partnerId = partnerId }
+}
+
+// WithBaseURI overrides the API base URI, useful for sandboxes and tests
+func WithBaseURI(baseURI string) Option {
+	return func(o *clientOptions) { o.config.baseURI = baseURI }
+}
+
+// WithAuthURI overrides the auth base URI
+func WithAuthURI(authURI string) Option {
+	return func(o *clientOptions) { o.config.authURI = authURI }
+}
+
+// WithHTTPClient sets the http.Client used to send requests, it is copied
+// so that WithTimeout does not modify the caller's client
+func WithHTTPClient(h *http.Client) Option {
+	return func(o *clientOptions) { o.httpClient = h }
+}
+
+// WithTimeout sets the timeout of each HTTP attempt
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *clientOptions) { o.timeout = timeout }
+}
+
+// WithDebug enables logging of requests and responses
+func WithDebug(debug bool) Option {
+	return func(o *clientOptions) { o.debug = debug }
+}
+
+// WithLogger sets the logger used for debug and error logging,
+// the standard logger is used by default
+func WithLogger(logger *log.Logger) Option {
+	return func(o *clientOptions) { o.logger = logger }
+}
+
+// WithMetrics attaches a metrics collector, see Client.SetMetrics
+func WithMetrics(m *Metrics) Option {
+	return func(o *clientOptions) { o.metrics = m }
+}
+
+// WithTracer sets the tracer, see Client.SetTracer
+func WithTracer(t Tracer) Option {
+	return func(o *clientOptions) { o.tracer = t }
+}
+
+// WithRateLimit limits the client to requestsPerSecond with bursts of up to burst requests
+func WithRateLimit(requestsPerSecond float64, burst int) Option {
+	return func(o *clientOptions) { o.rateLimiter = NewRateLimiter(requestsPerSecond, burst) }
+}
+
+// WithRateLimiter sets the rate limiter, share one RateLimiter between
+// clients to enforce a global budget
+func WithRateLimiter(l RateLimiter) Option {
+	return func(o *clientOptions) { o.rateLimiter = l }
+}
+
+// WithRetryPolicy sets how failed requests are retried
+func WithRetryPolicy(p RetryPolicy) Option {
+	return func(o *clientOptions) { o.retry = p }
+}
@@ -0,0 +1,289 @@
+package dhanhq
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// OrderRequest places a regular order
+type OrderRequest struct {
+	DhanClientId      string  `json:"dhanClientId"`
+	CorrelationId     string  `json:"correlationId,omitempty"`
+	TransactionType   string  `json:"transactionType"`
+	ExchangeSegment   string  `json:"exchangeSegment"`
+	ProductType       string  `json:"productType"`
+	OrderType         string  `json:"orderType"`
+	Validity          string  `json:"validity"`
+	SecurityId        string  `json:"securityId"`
+	Quantity          int32   `json:"quantity"`
+	DisclosedQuantity int32   `json:"disclosedQuantity,omitempty"`
+	Price             float64 `json:"price"`
+	TriggerPrice      float64 `json:"triggerPrice,omitempty"`
+	AfterMarketOrder  bool    `json:"afterMarketOrder,omitempty"`
+	AmoTime           string  `json:"amoTime,omitempty"`
+	BoProfitValue     float64 `json:"boProfitValue,omitempty"`
+	BoStopLossValue   float64 `json:"boStopLossValue,omitempty"`
+}
+
+// ModifyOrderRequest modifies a pending order, LegName is only used for bracket and cover orders
+type ModifyOrderRequest struct {
+	DhanClientId      string  `json:"dhanClientId"`
+	OrderId           string  `json:"orderId"`
+	OrderType         string  `json:"orderType"`
+	LegName           string  `json:"legName,omitempty"`
+	Quantity          int32   `json:"quantity"`
+	Price             float64 `json:"price"`
+	DisclosedQuantity int32   `json:"disclosedQuantity,omitempty"`
+	TriggerPrice      float64 `json:"triggerPrice,omitempty"`
+	Validity          string  `json:"validity"`
+}
+
+// Order is an order as listed in the order book
+type Order struct {
+	DhanClientId        string  `json:"dhanClientId"`
+	OrderId             string  `json:"orderId"`
+	CorrelationId       string  `json:"correlationId"`
+	OrderStatus         string  `json:"orderStatus"`
+	TransactionType     string  `json:"transactionType"`
+	ExchangeSegment     string  `json:"exchangeSegment"`
+	ProductType         string  `json:"productType"`
+	OrderType           string  `json:"orderType"`
+	Validity            string  `json:"validity"`
+	TradingSymbol       string  `json:"tradingSymbol"`
+	SecurityId          string  `json:"securityId"`
+	Quantity            int32   `json:"quantity"`
+	DisclosedQuantity   int32   `json:"disclosedQuantity"`
+	Price               float64 `json:"price"`
+	TriggerPrice        float64 `json:"triggerPrice"`
+	AfterMarketOrder    bool    `json:"afterMarketOrder"`
+	BoProfitValue       float64 `json:"boProfitValue"`
+	BoStopLossValue     float64 `json:"boStopLossValue"`
+	LegName             string  `json:"legName"`
+	CreateTime          string  `json:"createTime"`
+	UpdateTime          string  `json:"updateTime"`
+	ExchangeTime        string  `json:"exchangeTime"`
+	DrvExpiryDate       string  `json:"drvExpiryDate"`
+	DrvOptionType       string  `json:"drvOptionType"`
+	DrvStrikePrice      float64 `json:"drvStrikePrice"`
+	OmsErrorCode        string  `json:"omsErrorCode"`
+	OmsErrorDescription string  `json:"omsErrorDescription"`
+	AlgoId              string  `json:"algoId"`
+	RemainingQuantity   int32   `json:"remainingQuantity"`
+	AverageTradedPrice  float64 `json:"averageTradedPrice"`
+	FilledQty           int32   `json:"filledQty"`
+}
+
+// Pending reports whether the order can still be modified or cancelled
+func (o Order) Pending() bool {
+	switch o.OrderStatus {
+	case OrderStatusTransit, OrderStatusPending, OrderStatusPartTraded:
+		return true
+	}
+	return false
+}
+
+// OrderResponse is returned when an order is placed, modified or cancelled
+type OrderResponse struct {
+	OrderId     string `json:"orderId"`
+	OrderStatus string `json:"orderStatus"`
+}
+
+// sendOrder sends an order request with a JSON body and decodes the OrderResponse
+func (c *Client) sendOrder(ctx context.Context, cfg *clientConfig, method, rURL string, body interface{}) (OrderResponse, error) {
+	headers := http.Header{
+		"Content-Type": {"application/json"},
+		"Accept":       {"application/json"},
+		"access-token": {cfg.accessToken},
+	}
+	resp, err := cfg.httpClient.DoJSONContext(ctx, method, rURL, nil, body, headers, nil)
+	if err != nil {
+		return OrderResponse{}, err
+	}
+	if err := checkResponse(resp); err != nil {
+		return OrderResponse{}, err
+	}
+
+	var orderResponse OrderResponse
+	if err := json.Unmarshal(resp.Body, &orderResponse); err != nil {
+		return OrderResponse{}, err
+	}
+	return orderResponse, nil
+}
+
+// PlaceOrder places a regular order
+func (c *Client) PlaceOrder(req OrderRequest) (OrderResponse, error) {
+	return c.PlaceOrderContext(context.Background(), req)
+}
+
+// PlaceOrderContext is PlaceOrder with a context for cancellation and tracing
+func (c *Client) PlaceOrderContext(ctx context.Context, req OrderRequest) (_ OrderResponse, err error) {
+	ctx, span := c.startSpan(ctx, "PlaceOrder",
+		Attribute{Key: AttributeSecurityIds, Value: []string{req.ExchangeSegment + ":" + req.SecurityId}})
+	defer func() { endSpan(span, err) }()
+
+	cfg, err := c.authorize(ctx)
+	if err != nil {
+		return OrderResponse{}, err
+	}
+	if req.DhanClientId == "" {
+		req.DhanClientId = cfg.dhanClientId
+	}
+	return c.sendOrder(ctx, cfg, http.MethodPost, cfg.baseURI+URIPlaceOrder, req)
+}
+
+// SliceOrder places an order above the freeze quantity as several orders
+// the exchange accepts, it returns the response of every slice
+func (c *Client) SliceOrder(req OrderRequest) ([]OrderResponse, error) {
+	return c.SliceOrderContext(context.Background(), req)
+}
+
+// SliceOrderContext is SliceOrder with a context for cancellation and tracing
+func (c *Client) SliceOrderContext(ctx context.Context, req OrderRequest) (_ []OrderResponse, err error) {
+	ctx, span := c.startSpan(ctx, "SliceOrder",
+		Attribute{Key: AttributeSecurityIds, Value: []string{req.ExchangeSegment + ":" + req.SecurityId}})
+	defer func() { endSpan(span, err) }()
+
+	cfg, err := c.authorize(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.DhanClientId == "" {
+		req.DhanClientId = cfg.dhanClientId
+	}
+	headers := http.Header{
+		"Content-Type": {"application/json"},
+		"Accept":       {"application/json"},
+		"access-token": {cfg.accessToken},
+	}
+	var orderResponses []OrderResponse
+	resp, err := cfg.httpClient.DoJSONContext(ctx, http.MethodPost, cfg.baseURI+URISliceOrder, nil, req, headers, &orderResponses)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkResponse(resp); err != nil {
+		return nil, err
+	}
+	return orderResponses, nil
+}
+
+// ModifyOrder modifies a pending order
+func (c *Client) ModifyOrder(req ModifyOrderRequest) (OrderResponse, error) {
+	return c.ModifyOrderContext(context.Background(), req)
+}
+
+// ModifyOrderContext is ModifyOrder with a context for cancellation and tracing
+func (c *Client) ModifyOrderContext(ctx context.Context, req ModifyOrderRequest) (_ OrderResponse, err error) {
+	ctx, span := c.startSpan(ctx, "ModifyOrder")
+	defer func() { endSpan(span, err) }()
+
+	cfg, err := c.authorize(ctx)
+	if err != nil {
+		return OrderResponse{}, err
+	}
+	if req.DhanClientId == "" {
+		req.DhanClientId = cfg.dhanClientId
+	}
+	rURL := cfg.baseURI + fmt.Sprintf(URIModifyPendingOrder, url.PathEscape(req.OrderId))
+	return c.sendOrder(ctx, cfg, http.MethodPut, rURL, req)
+}
+
+// CancelOrder cancels a pending order
+func (c *Client) CancelOrder(orderId string) (OrderResponse, error) {
+	return c.CancelOrderContext(context.Background(), orderId)
+}
+
+// CancelOrderContext is CancelOrder with a context for cancellation and tracing
+func (c *Client) CancelOrderContext(ctx context.Context, orderId string) (_ OrderResponse, err error) {
+	ctx, span := c.startSpan(ctx, "CancelOrder")
+	defer func() { endSpan(span, err) }()
+
+	cfg, err := c.authorize(ctx)
+	if err != nil {
+		return OrderResponse{}, err
+	}
+	rURL := cfg.baseURI + fmt.Sprintf(URICancelPendingOrder, url.PathEscape(orderId))
+	return c.sendOrder(ctx, cfg, http.MethodDelete, rURL, nil)
+}
+
+// GetOrders retrieves the order book of the day
+func (c *Client) GetOrders() ([]Order, error) {
+	return c.GetOrdersContext(context.Background())
+}
+
+// GetOrdersContext is GetOrders with a context for cancellation and tracing
+func (c *Client) GetOrdersContext(ctx context.Context) (_ []Order, err error) {
+	ctx, span := c.startSpan(ctx, "GetOrders")
+	defer func() { endSpan(span, err) }()
+
+	cfg, err := c.authorize(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var orders []Order
+	if err := c.getOrders(ctx, cfg, cfg.baseURI+URIGetOrders, &orders); err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+// GetOrderById retrieves the status of an order
+func (c *Client) GetOrderById(orderId string) (Order, error) {
+	return c.GetOrderByIdContext(context.Background(), orderId)
+}
+
+// GetOrderByIdContext is GetOrderById with a context for cancellation and tracing
+func (c *Client) GetOrderByIdContext(ctx context.Context, orderId string) (_ Order, err error) {
+	ctx, span := c.startSpan(ctx, "GetOrderById")
+	defer func() { endSpan(span, err) }()
+
+	cfg, err := c.authorize(ctx)
+	if err != nil {
+		return Order{}, err
+	}
+	// DhanHQ has been seen to return the order both on its own and in a list
+	var orders orderList
+	rURL := cfg.baseURI + fmt.Sprintf(URIGetOrderStatus, url.PathEscape(orderId))
+	if err := c.getOrders(ctx, cfg, rURL, &orders); err != nil {
+		return Order{}, err
+	}
+	if len(orders) == 0 {
+		return Order{}, fmt.Errorf("dhanhq: order %s not found", orderId)
+	}
+	return orders[0], nil
+}
+
+// orderList decodes a list of orders or a single order
+type orderList []Order
+
+func (l *orderList) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '{' {
+		var order Order
+		if err := json.Unmarshal(data, &order); err != nil {
+			return err
+		}
+		*l = orderList{order}
+		return nil
+	}
+	return json.Unmarshal(data, (*[]Order)(l))
+}
+
+// getOrders fetches rURL and decodes the response into orders
+func (c *Client) getOrders(ctx context.Context, cfg *clientConfig, rURL string, orders interface{}) error {
+	headers := http.Header{
+		"Accept":       {"application/json"},
+		"access-token": {cfg.accessToken},
+	}
+	resp, err := cfg.httpClient.DoContext(ctx, http.MethodGet, rURL, headers, nil)
+	if err != nil {
+		return err
+	}
+	if err := checkResponse(resp); err != nil {
+		return err
+	}
+	return json.Unmarshal(resp.Body, orders)
+}
@@ -0,0 +1,255 @@
+package dhanhq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ClientPool holds one Client per account, keyed by Dhan client id. All
+// clients share one HTTP transport and the pool's options, including one
+// rate limiter that is a global budget for the pool.
+type ClientPool struct {
+	mu          sync.RWMutex
+	clients     map[string]*Client
+	options     clientOptions
+	concurrency int
+}
+
+// DefaultPoolConcurrency is the number of accounts queried at once by bulk operations
+const DefaultPoolConcurrency = 8
+
+// DefaultPoolRateLimit is the requests per second shared by every client of a
+// pool created without WithRateLimit or WithRateLimiter
+const DefaultPoolRateLimit = 20
+
+// NewClientPool creates an empty pool, opts are applied to every client
+// added to it. Credentials passed in opts are ignored. Unless opts set a
+// rate limiter, the pool's clients share one limited to DefaultPoolRateLimit.
+func NewClientPool(opts ...Option) *ClientPool {
+	o := clientOptions{
+		config: clientConfig{
+			baseURI: baseURI,
+			authURI: authURI,
+		},
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.httpClient == nil {
+		// A dedicated transport with enough idle connections for every account
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.MaxIdleConnsPerHost = 64
+		o.httpClient = &http.Client{Transport: transport}
+	}
+	if o.rateLimiter == nil {
+		o.rateLimiter = NewRateLimiter(DefaultPoolRateLimit, DefaultPoolRateLimit)
+	}
+	o.config.dhanClientId = ""
+	o.config.accessToken = ""
+	o.config.tokenExpiry = time.Time{}
+	o.loadAccount = ""
+
+	return &ClientPool{
+		clients:     make(map[string]*Client),
+		options:     o,
+		concurrency: DefaultPoolConcurrency,
+	}
+}
+
+// SetConcurrency sets how many accounts bulk operations query at once
+func (p *ClientPool) SetConcurrency(n int) {
+	if n < 1 {
+		n = 1
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.concurrency = n
+}
+
+// Add adds or replaces the client of an account and returns it
+func (p *ClientPool) Add(dhanClientId, accessToken string) *Client {
+	o := p.options
+	o.config.dhanClientId = dhanClientId
+	o.config.accessToken = accessToken
+	client := newClient(o)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.clients[dhanClientId] = client
+	return client
+}
+
+// AddConsent adds or replaces the client of the account in a consumed
+// consent, keeping track of the token expiry
+func (p *ClientPool) AddConsent(resp ConsumeConsentResponse) (*Client, error) {
+	client := newClient(p.options)
+	if err := client.SetConsumedConsent(resp); err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.clients[resp.DhanClientId] = client
+	return client, nil
+}
+
+// AddFromStore adds the client of an account with the credentials
+// in the pool's token store, see WithTokenStore
+func (p *ClientPool) AddFromStore(dhanClientId string) (*Client, error) {
+	client := newClient(p.options)
+	if err := client.LoadCredentials(dhanClientId); err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.clients[dhanClientId] = client
+	return client, nil
+}
+
+// Get returns the client of an account
+func (p *ClientPool) Get(dhanClientId string) (*Client, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	client, ok := p.clients[dhanClientId]
+	return client, ok
+}
+
+// Remove removes the client of an account
+func (p *ClientPool) Remove(dhanClientId string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.clients, dhanClientId)
+}
+
+// Accounts returns the Dhan client ids in the pool, sorted
+func (p *ClientPool) Accounts() []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	ids := make([]string, 0, len(p.clients))
+	for id := range p.clients {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Len returns the number of accounts in the pool
+func (p *ClientPool) Len() int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return len(p.clients)
+}
+
+// AccountError is the error of a single account in a bulk operation
+type AccountError struct {
+	DhanClientId string
+	Err          error
+}
+
+func (e *AccountError) Error() string {
+	return fmt.Sprintf("account %s: %v", e.DhanClientId, e.Err)
+}
+
+func (e *AccountError) Unwrap() error {
+	return e.Err
+}
+
+// PoolResult holds the per-account outcome of a bulk operation
+type PoolResult[T any] struct {
+	Values map[string]T
+	Errors map[string]error
+}
+
+// Err joins the per-account errors as *AccountError, it is nil if every account succeeded
+func (r PoolResult[T]) Err() error {
+	if len(r.Errors) == 0 {
+		return nil
+	}
+	ids := make([]string, 0, len(r.Errors))
+	for id := range r.Errors {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	errs := make([]error, 0, len(ids))
+	for _, id := range ids {
+		errs = append(errs, &AccountError{DhanClientId: id, Err: r.Errors[id]})
+	}
+	return errors.Join(errs...)
+}
+
+// ForEachAccount calls fn concurrently for every account in the pool and
+// collects the values and errors per account
+func ForEachAccount[T any](ctx context.Context, p *ClientPool, fn func(ctx context.Context, client *Client) (T, error)) PoolResult[T] {
+	p.mu.RLock()
+	clients := make(map[string]*Client, len(p.clients))
+	for id, client := range p.clients {
+		clients[id] = client
+	}
+	concurrency := p.concurrency
+	p.mu.RUnlock()
+
+	result := PoolResult[T]{
+		Values: make(map[string]T),
+		Errors: make(map[string]error),
+	}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, concurrency)
+
+	for id, client := range clients {
+		wg.Add(1)
+		go func(id string, client *Client) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+			}
+			// A slot may free up after ctx is done, check again before calling fn
+			if err := ctx.Err(); err != nil {
+				mu.Lock()
+				result.Errors[id] = err
+				mu.Unlock()
+				return
+			}
+
+			value, err := fn(ctx, client)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				result.Errors[id] = err
+				return
+			}
+			result.Values[id] = value
+		}(id, client)
+	}
+	wg.Wait()
+	return result
+}
+
+// GetPositions fetches the positions of every account concurrently
+func (p *ClientPool) GetPositions(ctx context.Context) PoolResult[Positions] {
+	return ForEachAccount(ctx, p, func(ctx context.Context, client *Client) (Positions, error) {
+		return client.GetPositionsContext(ctx)
+	})
+}
+
+// GetHoldings fetches the holdings of every account concurrently
+func (p *ClientPool) GetHoldings(ctx context.Context) PoolResult[Holdings] {
+	return ForEachAccount(ctx, p, func(ctx context.Context, client *Client) (Holdings, error) {
+		return client.GetHoldingsContext(ctx)
+	})
+}
+
+// GetFundLimits fetches the fund limits of every account concurrently
+func (p *ClientPool) GetFundLimits(ctx context.Context) PoolResult[FundLimit] {
+	return ForEachAccount(ctx, p, func(ctx context.Context, client *Client) (FundLimit, error) {
+		return client.GetFundLimitContext(ctx)
+	})
+}
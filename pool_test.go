@@ -0,0 +1,148 @@
+package dhanhq
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClientPoolSharesRateLimiter(t *testing.T) {
+	pool := NewClientPool()
+	a, b := pool.Add("1000000001", "a"), pool.Add("1000000002", "b")
+	limiter := a.snapshot().httpClient.(*httpClient).limiter
+	if limiter == nil {
+		t.Fatal("pool clients have no rate limiter by default")
+	}
+	if b.snapshot().httpClient.(*httpClient).limiter != limiter {
+		t.Error("pool clients do not share the rate limiter")
+	}
+
+	custom := NewRateLimiter(5, 5)
+	pool = NewClientPool(WithRateLimiter(custom))
+	if got := pool.Add("1000000001", "a").snapshot().httpClient.(*httpClient).limiter; got != custom {
+		t.Errorf("pool client limiter is %v, want the one passed to NewClientPool", got)
+	}
+}
+
+func TestClientPoolIgnoresCredentialOptions(t *testing.T) {
+	pool := NewClientPool(WithCredentials("1000000009", "other"))
+	cfg := pool.Add("1000000001", "token").snapshot()
+	if cfg.dhanClientId != "1000000001" || cfg.accessToken != "token" {
+		t.Errorf("client credentials are %s/%s, want the added account", cfg.dhanClientId, cfg.accessToken)
+	}
+	if ids := pool.Accounts(); len(ids) != 1 || ids[0] != "1000000001" {
+		t.Errorf("accounts are %v", ids)
+	}
+}
+
+func TestClientPoolGetHoldings(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Header.Get("access-token") {
+		case "expired":
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"errorType":"Invalid_Authentication","errorCode":"DH-901","errorMessage":"Client ID or user generated access token is invalid or expired."}`))
+		default:
+			w.Write([]byte(`[{"securityId":"1333","totalQty":10}]`))
+		}
+	}))
+	defer srv.Close()
+
+	pool := NewClientPool(WithBaseURI(srv.URL))
+	pool.Add("1000000001", "token")
+	pool.Add("1000000002", "expired")
+	pool.Add("1000000003", "token")
+
+	result := pool.GetHoldings(context.Background())
+	if len(result.Values) != 2 || len(result.Values["1000000001"].Holdings) != 1 || len(result.Values["1000000003"].Holdings) != 1 {
+		t.Errorf("values are %v, want the holdings of the two valid accounts", result.Values)
+	}
+	if len(result.Errors) != 1 {
+		t.Fatalf("errors are %v, want one for 1000000002", result.Errors)
+	}
+	var errResp ErrorResponse
+	if !errors.As(result.Errors["1000000002"], &errResp) || errResp.ErrorCode != "DH-901" {
+		t.Errorf("error of 1000000002 is %v, want DH-901", result.Errors["1000000002"])
+	}
+
+	var accountErr *AccountError
+	if err := result.Err(); !errors.As(err, &accountErr) || accountErr.DhanClientId != "1000000002" {
+		t.Errorf("Err() = %v, want an AccountError for 1000000002", err)
+	}
+}
+
+func TestForEachAccount(t *testing.T) {
+	pool := NewClientPool()
+	for _, id := range []string{"1", "2", "3", "4", "5", "6"} {
+		pool.Add(id, "token")
+	}
+	pool.SetConcurrency(2)
+
+	var running, maxRunning atomic.Int32
+	failure := errors.New("failed")
+	result := ForEachAccount(context.Background(), pool, func(ctx context.Context, client *Client) (string, error) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			max := maxRunning.Load()
+			if n <= max || maxRunning.CompareAndSwap(max, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+
+		id := client.snapshot().dhanClientId
+		if id == "3" || id == "5" {
+			return "", failure
+		}
+		return "ok " + id, nil
+	})
+
+	if got := maxRunning.Load(); got > 2 {
+		t.Errorf("%d accounts ran at once, want at most 2", got)
+	}
+	if len(result.Values) != 4 || result.Values["1"] != "ok 1" {
+		t.Errorf("values are %v", result.Values)
+	}
+	if len(result.Errors) != 2 || result.Errors["3"] != failure || result.Errors["5"] != failure {
+		t.Errorf("errors are %v, want accounts 3 and 5", result.Errors)
+	}
+	if err := result.Err(); !errors.Is(err, failure) || err.Error() != "account 3: failed\naccount 5: failed" {
+		t.Errorf("Err() = %q", err)
+	}
+
+	if err := (PoolResult[string]{}).Err(); err != nil {
+		t.Errorf("Err() of a successful result is %v", err)
+	}
+}
+
+func TestForEachAccountCancelled(t *testing.T) {
+	pool := NewClientPool()
+	pool.Add("1", "token")
+	pool.Add("2", "token")
+	pool.SetConcurrency(1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var calls atomic.Int32
+	result := ForEachAccount(ctx, pool, func(ctx context.Context, client *Client) (bool, error) {
+		calls.Add(1)
+		cancel()
+		return true, nil
+	})
+
+	// The first account runs and cancels, the other one never gets a slot
+	if calls.Load() != 1 || len(result.Values) != 1 {
+		t.Errorf("fn called %d times with values %v, want 1", calls.Load(), result.Values)
+	}
+	for id, err := range result.Errors {
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("error of %s is %v, want context.Canceled", id, err)
+		}
+	}
+	if len(result.Errors) != 1 {
+		t.Errorf("errors are %v, want one cancelled account", result.Errors)
+	}
+}
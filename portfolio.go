@@ -1,6 +1,7 @@
 package dhanhq
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -85,14 +86,29 @@ type ConvertPositionRequest struct {
 
 // GetPositions retrieves the positions for a given client
 func (c *Client) GetPositions() (Positions, error) {
+	return c.GetPositionsContext(context.Background())
+}
+
+// GetPositionsContext is GetPositions with a context for cancellation and tracing
+func (c *Client) GetPositionsContext(ctx context.Context) (_ Positions, err error) {
+	ctx, span := c.startSpan(ctx, "GetPositions")
+	defer func() { endSpan(span, err) }()
+
+	cfg, err := c.authorize(ctx)
+	if err != nil {
+		return Positions{}, err
+	}
 	headers := http.Header{
-		"access-token": {c.accessToken},
+		"access-token": {cfg.accessToken},
 	}
 
-	resp, err := c.httpClient.Do(http.MethodGet, c.baseURI+URIPositions, headers, nil)
+	resp, err := cfg.httpClient.DoContext(ctx, http.MethodGet, cfg.baseURI+URIPositions, headers, nil)
 	if err != nil {
 		return Positions{}, err
 	}
+	if err = checkResponse(resp); err != nil {
+		return Positions{}, err
+	}
 
 	var positionsSlice []Position
 	if err = json.Unmarshal(resp.Body, &positionsSlice); err != nil {
@@ -107,11 +123,23 @@ func (c *Client) GetPositions() (Positions, error) {
 
 // GetHoldings retrieves the holdings for a given client
 func (c *Client) GetHoldings() (Holdings, error) {
+	return c.GetHoldingsContext(context.Background())
+}
+
+// GetHoldingsContext is GetHoldings with a context for cancellation and tracing
+func (c *Client) GetHoldingsContext(ctx context.Context) (_ Holdings, err error) {
+	ctx, span := c.startSpan(ctx, "GetHoldings")
+	defer func() { endSpan(span, err) }()
+
+	cfg, err := c.authorize(ctx)
+	if err != nil {
+		return Holdings{}, err
+	}
 	headers := http.Header{
-		"access-token": {c.accessToken},
+		"access-token": {cfg.accessToken},
 	}
 
-	resp, err := c.httpClient.Do(http.MethodGet, c.baseURI+URIHoldings, headers, nil)
+	resp, err := cfg.httpClient.DoContext(ctx, http.MethodGet, cfg.baseURI+URIHoldings, headers, nil)
 	if err != nil {
 		return Holdings{}, err
 	}
@@ -126,8 +154,7 @@ func (c *Client) GetHoldings() (Holdings, error) {
 					Holdings: []Holding{},
 				}, nil
 			}
-			return Holdings{}, fmt.Errorf("API error: %s (Code: %s, Type: %s)",
-				errorResp.ErrorMessage, errorResp.ErrorCode, errorResp.ErrorType)
+			return Holdings{}, errorResp
 		}
 	}
 
@@ -143,13 +170,28 @@ func (c *Client) GetHoldings() (Holdings, error) {
 }
 
 func (c *Client) GetFundLimit() (FundLimit, error) {
+	return c.GetFundLimitContext(context.Background())
+}
+
+// GetFundLimitContext is GetFundLimit with a context for cancellation and tracing
+func (c *Client) GetFundLimitContext(ctx context.Context) (_ FundLimit, err error) {
+	ctx, span := c.startSpan(ctx, "GetFundLimit")
+	defer func() { endSpan(span, err) }()
+
+	cfg, err := c.authorize(ctx)
+	if err != nil {
+		return FundLimit{}, err
+	}
 	headers := http.Header{
-		"access-token": {c.accessToken},
+		"access-token": {cfg.accessToken},
 	}
-	resp, err := c.httpClient.Do(http.MethodGet, c.baseURI+URIFundLimit, headers, nil)
+	resp, err := cfg.httpClient.DoContext(ctx, http.MethodGet, cfg.baseURI+URIFundLimit, headers, nil)
 	if err != nil {
 		return FundLimit{}, err
 	}
+	if err = checkResponse(resp); err != nil {
+		return FundLimit{}, err
+	}
 
 	var fundLimit FundLimit
 	if err = json.Unmarshal(resp.Body, &fundLimit); err != nil {
@@ -160,26 +202,30 @@ func (c *Client) GetFundLimit() (FundLimit, error) {
 }
 
 func (c *Client) ConvertPosition(req ConvertPositionRequest) error {
-	headers := http.Header{
-		"access-token": {c.accessToken},
-	}
+	return c.ConvertPositionContext(context.Background(), req)
+}
+
+// ConvertPositionContext is ConvertPosition with a context for cancellation and tracing
+func (c *Client) ConvertPositionContext(ctx context.Context, req ConvertPositionRequest) (err error) {
+	ctx, span := c.startSpan(ctx, "ConvertPosition",
+		Attribute{Key: AttributeSecurityIds, Value: []string{req.ExchangeSegment + ":" + req.SecurityId}})
+	defer func() { endSpan(span, err) }()
 
-	body, err := json.Marshal(req)
+	cfg, err := c.authorize(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to marshal request: %w", err)
+		return err
+	}
+	headers := http.Header{
+		"Content-Type": {"application/json"},
+		"access-token": {cfg.accessToken},
+	}
+	if req.DhanClientId == "" {
+		req.DhanClientId = cfg.dhanClientId
 	}
 
-	resp, err := c.httpClient.DoJSON(http.MethodPost, c.baseURI+URIPositionConvert, nil, body, headers, nil)
-
+	resp, err := cfg.httpClient.DoJSONContext(ctx, http.MethodPost, cfg.baseURI+URIPositionConvert, nil, req, headers, nil)
 	if err != nil {
 		return fmt.Errorf("failed to convert position: %w", err)
 	}
-
-	var errorResp ErrorResponse
-	if err = json.Unmarshal(resp.Body, &errorResp); err == nil && errorResp.ErrorMessage != "" {
-		return fmt.Errorf("API error: %s (Code: %s, Type: %s)",
-			errorResp.ErrorMessage, errorResp.ErrorCode, errorResp.ErrorType)
-	}
-
-	return nil
+	return checkResponse(resp)
 }
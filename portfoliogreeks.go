@@ -0,0 +1,319 @@
+package dhanhq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/tradewithcanvas/godhanhq/greeks"
+)
+
+// GreeksRequest configures PortfolioGreeks
+type GreeksRequest struct {
+	// Instruments provides the option contracts, the underlyings and the
+	// futures to hedge with, it should be the detailed instrument master
+	Instruments *InstrumentMaster
+
+	// Rate is the risk free rate, 0.065 for 6.5%
+	Rate float64
+
+	// Volatilities sets the volatility of the options by underlying symbol,
+	// the others are implied from their last traded price
+	Volatilities map[string]float64
+
+	// UnderlyingPrices overrides the prices fetched with GetLTP, by underlying symbol
+	UnderlyingPrices map[string]float64
+
+	// Now is the time the options are valued at, time.Now() by default
+	Now time.Time
+}
+
+// PositionGreeks are the greeks of one position. Greeks are per unit of the
+// option, Delta, Gamma, Theta and Vega are for the whole position: delta in
+// units of the underlying, theta per day and vega per volatility point.
+type PositionGreeks struct {
+	Position        Position
+	Underlying      string
+	UnderlyingPrice float64
+	Volatility      float64
+	Greeks          greeks.Greeks
+	Delta           float64
+	Gamma           float64
+	Theta           float64
+	Vega            float64
+
+	// Err is set when the position could not be valued, it is then left out of the totals
+	Err error
+}
+
+// HedgeSuggestion is the futures trade making the delta of an underlying as
+// close to zero as whole lots allow
+type HedgeSuggestion struct {
+	Future          Instrument
+	TransactionType string
+	Lots            int32
+	Quantity        int32
+
+	// ResidualDelta is the delta of the underlying after the hedge
+	ResidualDelta float64
+}
+
+// Order returns the market order placing the hedge
+func (h HedgeSuggestion) Order(productType string) OrderRequest {
+	return OrderRequest{
+		TransactionType: h.TransactionType,
+		ExchangeSegment: h.Future.ExchangeSegment,
+		ProductType:     productType,
+		OrderType:       OrderTypeMarket,
+		Validity:        ValidityDay,
+		SecurityId:      h.Future.SecurityId,
+		Quantity:        h.Quantity,
+	}
+}
+
+// UnderlyingGreeks sums the greeks of the positions on one underlying,
+// RupeeDelta is Delta times the underlying price
+type UnderlyingGreeks struct {
+	Underlying      string
+	UnderlyingPrice float64
+	Delta           float64
+	RupeeDelta      float64
+	Gamma           float64
+	Theta           float64
+	Vega            float64
+	Positions       []PositionGreeks
+
+	// Hedge is nil when no future of the underlying is in the instrument
+	// master or the delta is below half a lot
+	Hedge *HedgeSuggestion
+}
+
+// PortfolioGreeks sums the greeks of the open positions by underlying. Deltas
+// in different underlyings do not add up, so the portfolio total is the
+// rupee delta, the change in value for a 1 rupee move of every underlying
+// scaled by its price. Theta and Vega are in rupees and add up.
+type PortfolioGreeks struct {
+	Time        time.Time
+	Underlyings []UnderlyingGreeks
+	RupeeDelta  float64
+	Theta       float64
+	Vega        float64
+}
+
+// Err joins the errors of the positions that could not be valued
+func (p PortfolioGreeks) Err() error {
+	var errs []error
+	for _, underlying := range p.Underlyings {
+		for _, position := range underlying.Positions {
+			errs = append(errs, position.Err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// PortfolioGreeks values the open positions with Black-Scholes on the
+// underlying and option prices from one GetLTP request and sums their delta,
+// gamma, theta and vega by underlying, with a delta-neutral hedge in the
+// nearest future. Futures and cash positions count as a delta of one per unit.
+func (c *Client) PortfolioGreeks(req GreeksRequest) (PortfolioGreeks, error) {
+	return c.PortfolioGreeksContext(context.Background(), req)
+}
+
+// PortfolioGreeksContext is PortfolioGreeks with a context for cancellation and tracing
+func (c *Client) PortfolioGreeksContext(ctx context.Context, req GreeksRequest) (PortfolioGreeks, error) {
+	if req.Instruments == nil {
+		return PortfolioGreeks{}, errors.New("dhanhq: portfolio greeks need an instrument master")
+	}
+	now := req.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	positions, err := c.GetPositionsContext(ctx)
+	if err != nil {
+		return PortfolioGreeks{}, err
+	}
+
+	// The underlyings and the options without a set volatility, whose
+	// volatility is implied from their last price, are priced in one request
+	securities, symbols := underlyingSecurities(positions, req.Instruments)
+	for _, position := range positions.Positions {
+		underlying := positionUnderlying(position, req.Instruments)
+		if position.NetQty == 0 || !isOption(position, req.Instruments) || req.Volatilities[underlying] > 0 {
+			continue
+		}
+		id, err := strconv.Atoi(position.SecurityId)
+		if err == nil && !slices.Contains(securities[position.ExchangeSegment], id) {
+			securities[position.ExchangeSegment] = append(securities[position.ExchangeSegment], id)
+		}
+	}
+	var ltp LTPResponse
+	if len(securities) > 0 {
+		if ltp, err = c.GetLTPContext(ctx, securities); err != nil {
+			return PortfolioGreeks{}, err
+		}
+	}
+
+	prices := underlyingPrices(ltp, symbols)
+	for underlying, price := range req.UnderlyingPrices {
+		prices[underlying] = price
+	}
+	lastPrices := make(map[string]float64)
+	for segment, quotes := range ltp.Data {
+		for securityId, quote := range quotes {
+			lastPrices[instrumentKey(segment, securityId)] = quote.LastPrice
+		}
+	}
+
+	portfolio := PortfolioGreeks{Time: now}
+	index := make(map[string]int)
+	for _, position := range positions.Positions {
+		if position.NetQty == 0 {
+			continue
+		}
+		pg := positionGreeks(position, prices, lastPrices, req, now)
+		i, ok := index[pg.Underlying]
+		if !ok {
+			i = len(portfolio.Underlyings)
+			index[pg.Underlying] = i
+			portfolio.Underlyings = append(portfolio.Underlyings, UnderlyingGreeks{
+				Underlying:      pg.Underlying,
+				UnderlyingPrice: prices[pg.Underlying],
+			})
+		}
+		u := &portfolio.Underlyings[i]
+		u.Positions = append(u.Positions, pg)
+		if pg.Err != nil {
+			continue
+		}
+		u.Delta += pg.Delta
+		u.Gamma += pg.Gamma
+		u.Theta += pg.Theta
+		u.Vega += pg.Vega
+	}
+
+	futures := nearestFutures(req.Instruments, now)
+	for i := range portfolio.Underlyings {
+		u := &portfolio.Underlyings[i]
+		if future, ok := futures[u.Underlying]; ok {
+			u.Hedge = hedgeSuggestion(u.Delta, future)
+		}
+		u.RupeeDelta = u.Delta * u.UnderlyingPrice
+		portfolio.RupeeDelta += u.RupeeDelta
+		portfolio.Theta += u.Theta
+		portfolio.Vega += u.Vega
+	}
+	sort.SliceStable(portfolio.Underlyings, func(i, j int) bool {
+		return math.Abs(portfolio.Underlyings[i].RupeeDelta) > math.Abs(portfolio.Underlyings[j].RupeeDelta)
+	})
+	return portfolio, nil
+}
+
+// positionGreeks values one open position
+func positionGreeks(position Position, prices, lastPrices map[string]float64, req GreeksRequest, now time.Time) PositionGreeks {
+	pg := PositionGreeks{
+		Position:   position,
+		Underlying: positionUnderlying(position, req.Instruments),
+	}
+	pg.UnderlyingPrice = prices[pg.Underlying]
+	quantity := float64(position.NetQty)
+	if position.Multiplier > 1 {
+		quantity *= float64(position.Multiplier)
+	}
+
+	if !isOption(position, req.Instruments) {
+		pg.Greeks = greeks.Greeks{Price: pg.UnderlyingPrice, Delta: 1}
+		pg.Delta = quantity
+		return pg
+	}
+
+	params, err := OptionParams(position, req.Instruments, now)
+	if err != nil {
+		pg.Err = err
+		return pg
+	}
+	if pg.UnderlyingPrice <= 0 {
+		pg.Err = fmt.Errorf("dhanhq: no price for %s, the underlying of %s", pg.Underlying, position.TradingSymbol)
+		return pg
+	}
+	params.Underlying = pg.UnderlyingPrice
+	params.Rate = req.Rate
+	params.Volatility = req.Volatilities[pg.Underlying]
+	if params.Volatility <= 0 && params.Expiry > 0 {
+		optionPrice := lastPrices[instrumentKey(position.ExchangeSegment, position.SecurityId)]
+		if optionPrice <= 0 {
+			pg.Err = fmt.Errorf("dhanhq: no last price for %s to imply its volatility", position.TradingSymbol)
+			return pg
+		}
+		params.Volatility, err = greeks.ImpliedVolatility(params, optionPrice)
+		if err != nil {
+			pg.Err = fmt.Errorf("dhanhq: implied volatility of %s: %w", position.TradingSymbol, err)
+			return pg
+		}
+	}
+
+	pg.Volatility = params.Volatility
+	pg.Greeks = greeks.Compute(params)
+	pg.Delta = pg.Greeks.Delta * quantity
+	pg.Gamma = pg.Greeks.Gamma * quantity
+	pg.Theta = pg.Greeks.Theta * quantity
+	pg.Vega = pg.Greeks.Vega * quantity
+	return pg
+}
+
+// isOption reports whether a position is on an option
+func isOption(position Position, master *InstrumentMaster) bool {
+	if master != nil {
+		if instrument, ok := master.Lookup(position.ExchangeSegment, position.SecurityId); ok {
+			return instrument.OptionType != ""
+		}
+	}
+	_, err := greeks.ParseOptionType(position.DrvOptionType)
+	return err == nil
+}
+
+// nearestFutures returns the unexpired future with the nearest expiry of
+// every underlying, preferring NSE to BSE contracts
+func nearestFutures(master *InstrumentMaster, now time.Time) map[string]Instrument {
+	futures := make(map[string]Instrument)
+	for _, instrument := range master.Instruments() {
+		if instrument.InstrumentType != InstrumentTypeIndexFuture && instrument.InstrumentType != InstrumentTypeStockFuture {
+			continue
+		}
+		if instrument.LotSize <= 0 || instrument.ExpiryDate.Before(now) {
+			continue
+		}
+		current, ok := futures[instrument.UnderlyingSymbol]
+		switch {
+		case !ok,
+			current.ExchangeSegment != ExchangeSegmentFNONSE && instrument.ExchangeSegment == ExchangeSegmentFNONSE,
+			current.ExchangeSegment == instrument.ExchangeSegment && instrument.ExpiryDate.Before(current.ExpiryDate):
+			futures[instrument.UnderlyingSymbol] = instrument
+		}
+	}
+	return futures
+}
+
+// hedgeSuggestion returns the futures trade neutralizing delta, nil if it is below half a lot
+func hedgeSuggestion(delta float64, future Instrument) *HedgeSuggestion {
+	lots := int32(math.Round(-delta / float64(future.LotSize)))
+	if lots == 0 {
+		return nil
+	}
+	hedge := &HedgeSuggestion{
+		Future:          future,
+		TransactionType: TransactionTypeBuy,
+		Lots:            lots,
+		ResidualDelta:   delta + float64(lots*future.LotSize),
+	}
+	if lots < 0 {
+		hedge.TransactionType = TransactionTypeSell
+		hedge.Lots = -lots
+	}
+	hedge.Quantity = hedge.Lots * future.LotSize
+	return hedge
+}
@@ -0,0 +1,76 @@
+package dhanhq
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPortfolioGreeksPricesInOneRequest(t *testing.T) {
+	now := time.Date(2024, time.May, 2, 10, 0, 0, 0, istLocation)
+	expiry := time.Date(2024, time.May, 30, 0, 0, 0, 0, istLocation)
+	master := NewInstrumentMaster(
+		Instrument{ExchangeSegment: ExchangeSegmentFNONSE, SecurityId: "43001", InstrumentType: InstrumentTypeIndexOption, TradingSymbol: "NIFTY-May2024-22500-CE",
+			UnderlyingSymbol: "NIFTY", UnderlyingSecurityId: "13", LotSize: 25, ExpiryDate: expiry, StrikePrice: 22500, OptionType: "CALL"},
+		Instrument{ExchangeSegment: ExchangeSegmentFNONSE, SecurityId: "35001", InstrumentType: InstrumentTypeIndexFuture, TradingSymbol: "NIFTY-May2024-FUT",
+			UnderlyingSymbol: "NIFTY", UnderlyingSecurityId: "13", LotSize: 25, ExpiryDate: expiry},
+	)
+
+	var mu sync.Mutex
+	var requests []MarketDataInput
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case URIPositions:
+			w.Write([]byte(`[
+				{"tradingSymbol":"NIFTY-May2024-22500-CE","securityId":"43001","exchangeSegment":"NSE_FNO","positionType":"LONG","netQty":50},
+				{"tradingSymbol":"NIFTY-May2024-FUT","securityId":"35001","exchangeSegment":"NSE_FNO","positionType":"SHORT","netQty":-25}
+			]`))
+		case URIMarketfeedLTP:
+			var input MarketDataInput
+			json.NewDecoder(r.Body).Decode(&input)
+			mu.Lock()
+			requests = append(requests, input)
+			mu.Unlock()
+			w.Write([]byte(`{"data":{"IDX_I":{"13":{"last_price":22500}},"NSE_FNO":{"43001":{"last_price":300}}},"status":"success"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+	client := NewClient(WithBaseURI(srv.URL), WithCredentials("client", "token"))
+
+	portfolio, err := client.PortfolioGreeksContext(context.Background(), GreeksRequest{Instruments: master, Rate: 0.065, Now: now})
+	if err != nil {
+		t.Fatalf("PortfolioGreeks: %v", err)
+	}
+	if len(requests) != 1 {
+		t.Fatalf("made %d LTP requests, want 1", len(requests))
+	}
+	if !slices.Equal(requests[0][ExchangeSegmentIndex], []int{13}) || !slices.Equal(requests[0][ExchangeSegmentFNONSE], []int{43001}) {
+		t.Errorf("requested %v, want the index and the option", requests[0])
+	}
+	if err := portfolio.Err(); err != nil {
+		t.Fatalf("positions not valued: %v", err)
+	}
+
+	if len(portfolio.Underlyings) != 1 {
+		t.Fatalf("underlyings are %+v, want NIFTY only", portfolio.Underlyings)
+	}
+	nifty := portfolio.Underlyings[0]
+	if nifty.Underlying != "NIFTY" || nifty.UnderlyingPrice != 22500 {
+		t.Errorf("underlying is %s at %g, want NIFTY at 22500", nifty.Underlying, nifty.UnderlyingPrice)
+	}
+	option := nifty.Positions[0]
+	if option.Volatility <= 0 || option.Volatility > 1 {
+		t.Errorf("implied volatility is %g", option.Volatility)
+	}
+	// An at the money call has a delta a little above one half
+	if option.Greeks.Delta < 0.5 || option.Greeks.Delta > 0.6 {
+		t.Errorf("option delta is %g", option.Greeks.Delta)
+	}
+}
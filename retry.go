@@ -0,0 +1,84 @@
+package dhanhq
+
+import (
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how failed HTTP attempts are retried. Rate limited
+// responses (HTTP 429) are always safe to retry because DhanHQ did not act
+// on them, other failures are only retried for idempotent methods unless
+// RetryNonIdempotent is set, since retrying a POST may place an order twice.
+type RetryPolicy struct {
+	// MaxRetries is the number of retries after the first attempt, 0 disables retries
+	MaxRetries int
+
+	// MinBackoff and MaxBackoff bound the exponential backoff between attempts
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+
+	// RetryStatuses lists the HTTP status codes that are retried
+	RetryStatuses []int
+
+	// RetryNonIdempotent allows retrying POST and PATCH requests on
+	// network errors and RetryStatuses other than 429
+	RetryNonIdempotent bool
+}
+
+// DefaultRetryPolicy retries rate limited and transient server errors up to 3 times
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries: 3,
+	MinBackoff: 200 * time.Millisecond,
+	MaxBackoff: 5 * time.Second,
+	RetryStatuses: []int{
+		http.StatusTooManyRequests,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout,
+	},
+}
+
+// shouldRetry reports whether an attempt that ended with resp and err may be retried
+func (p RetryPolicy) shouldRetry(method string, attempt int, resp HTTPResponse, err error) bool {
+	if attempt >= p.MaxRetries {
+		return false
+	}
+	idempotent := method != http.MethodPost && method != http.MethodPatch
+	if err != nil {
+		return resp.Response == nil && (idempotent || p.RetryNonIdempotent)
+	}
+	if resp.Response == nil {
+		return false
+	}
+	for _, status := range p.RetryStatuses {
+		if resp.Response.StatusCode == status {
+			return status == http.StatusTooManyRequests || idempotent || p.RetryNonIdempotent
+		}
+	}
+	return false
+}
+
+// backoff returns how long to wait before the given retry, honouring a
+// Retry-After header on the previous response
+func (p RetryPolicy) backoff(attempt int, resp HTTPResponse) time.Duration {
+	if resp.Response != nil {
+		if seconds, err := strconv.Atoi(resp.Response.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+			return time.Duration(seconds) * time.Second
+		}
+	}
+	minBackoff, maxBackoff := p.MinBackoff, p.MaxBackoff
+	if minBackoff <= 0 {
+		minBackoff = DefaultRetryPolicy.MinBackoff
+	}
+	if maxBackoff < minBackoff {
+		maxBackoff = minBackoff
+	}
+	d := minBackoff << attempt
+	if d <= 0 || d > maxBackoff {
+		d = maxBackoff
+	}
+	// Full jitter keeps bots sharing a budget from retrying in lockstep
+	return time.Duration(rand.Int64N(int64(d)) + 1)
+}
@@ -0,0 +1,177 @@
+package dhanhq
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fastRetries retries quickly so that the tests do not sleep
+var fastRetries = RetryPolicy{
+	MaxRetries:    3,
+	MinBackoff:    time.Millisecond,
+	MaxBackoff:    2 * time.Millisecond,
+	RetryStatuses: DefaultRetryPolicy.RetryStatuses,
+}
+
+func TestRetries(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		status   int
+		policy   RetryPolicy
+		attempts int64
+	}{
+		{"GET retried on 429", http.MethodGet, http.StatusTooManyRequests, fastRetries, 4},
+		{"GET retried on 503", http.MethodGet, http.StatusServiceUnavailable, fastRetries, 4},
+		{"POST retried on 429", http.MethodPost, http.StatusTooManyRequests, fastRetries, 4},
+		{"POST not retried on 503", http.MethodPost, http.StatusServiceUnavailable, fastRetries, 1},
+		{"GET not retried on 400", http.MethodGet, http.StatusBadRequest, fastRetries, 1},
+		{"GET not retried on 401", http.MethodGet, http.StatusUnauthorized, fastRetries, 1},
+		{"no retries by default", http.MethodGet, http.StatusTooManyRequests, RetryPolicy{}, 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var attempts atomic.Int64
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				attempts.Add(1)
+				w.WriteHeader(test.status)
+				w.Write([]byte(`{"errorType":"Error","errorCode":"DH-904","errorMessage":"failed"}`))
+			}))
+			defer srv.Close()
+
+			h := &httpClient{client: srv.Client(), retry: test.policy}
+			resp, err := h.DoRawContext(context.Background(), test.method, srv.URL+URIPositions, nil, http.Header{})
+			if err != nil {
+				t.Fatalf("DoRaw: %v", err)
+			}
+			if resp.Response.StatusCode != test.status {
+				t.Errorf("status is %d, want %d", resp.Response.StatusCode, test.status)
+			}
+			if got := attempts.Load(); got != test.attempts {
+				t.Errorf("sent %d attempts, want %d", got, test.attempts)
+			}
+		})
+	}
+}
+
+func TestRetrySucceedsAfterRateLimit(t *testing.T) {
+	var attempts atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) < 3 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`[]`))
+	}))
+	defer srv.Close()
+
+	client := NewClient(WithBaseURI(srv.URL), WithCredentials("client", "token"), WithRetryPolicy(fastRetries))
+	if _, err := client.GetPositions(); err != nil {
+		t.Fatalf("GetPositions: %v", err)
+	}
+	if got := attempts.Load(); got != 3 {
+		t.Errorf("sent %d attempts, want 3", got)
+	}
+}
+
+func TestRetryReturnsStatusErrorForNonJSONBody(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+		w.Write([]byte("<html>bad gateway</html>"))
+	}))
+	defer srv.Close()
+
+	h := &httpClient{client: srv.Client(), retry: fastRetries}
+	_, err := h.DoRawContext(context.Background(), http.MethodGet, srv.URL, nil, http.Header{})
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusBadGateway {
+		t.Errorf("got %v, want a StatusError for 502", err)
+	}
+}
+
+func TestRetryStopsWhenContextIsDone(t *testing.T) {
+	var attempts atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	policy := fastRetries
+	policy.MinBackoff, policy.MaxBackoff = time.Hour, time.Hour
+	h := &httpClient{client: srv.Client(), retry: policy}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := h.DoRawContext(ctx, http.MethodGet, srv.URL, nil, http.Header{}); err != nil {
+		t.Fatalf("DoRaw: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("returned after %v, want soon after the context is done", elapsed)
+	}
+	if got := attempts.Load(); got != 1 {
+		t.Errorf("sent %d attempts, want 1", got)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	p := RetryPolicy{MinBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+	for attempt := 0; attempt < 10; attempt++ {
+		limit := min(p.MinBackoff<<attempt, p.MaxBackoff)
+		for i := 0; i < 100; i++ {
+			if d := p.backoff(attempt, HTTPResponse{}); d <= 0 || d > limit {
+				t.Fatalf("backoff of attempt %d is %v, want in (0, %v]", attempt, d, limit)
+			}
+		}
+	}
+
+	resp := HTTPResponse{Response: &http.Response{Header: http.Header{"Retry-After": {"2"}}}}
+	if d := p.backoff(0, resp); d != 2*time.Second {
+		t.Errorf("backoff with Retry-After 2 is %v, want 2s", d)
+	}
+}
+
+func TestTokenBucket(t *testing.T) {
+	limiter := NewRateLimiter(20, 2)
+	ctx := context.Background()
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		if err := limiter.Wait(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The burst of 2 is free, the next 2 wait 50ms each
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond || elapsed > time.Second {
+		t.Errorf("4 requests at 20/s with a burst of 2 took %v, want about 100ms", elapsed)
+	}
+}
+
+func TestTokenBucketContextCancellation(t *testing.T) {
+	limiter := NewRateLimiter(0.1, 1)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if err := limiter.Wait(ctx); err != nil {
+		t.Fatalf("first request: %v", err)
+	}
+	if err := limiter.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestTokenBucketWithoutRate(t *testing.T) {
+	limiter := NewRateLimiter(0, 0)
+	for i := 0; i < 100; i++ {
+		if err := limiter.Wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package dhanhq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Constraints that bind a position size
+const (
+	SizingConstraintRisk    = "RISK"
+	SizingConstraintMargin  = "MARGIN"
+	SizingConstraintMaxLots = "MAX_LOTS"
+)
+
+// SizingRequest describes a trade to size
+type SizingRequest struct {
+	Instrument      Instrument
+	TransactionType string
+	ProductType     string
+	Price           float64
+
+	// StopLoss must be below Price for a BUY and above it for a SELL
+	StopLoss float64
+
+	// RiskBudget is the most that may be lost if the stop loss is hit
+	RiskBudget float64
+
+	// MaxLots caps the size, zero for no cap
+	MaxLots int32
+
+	// CashOnly sizes against the withdrawable balance when it is lower than
+	// the available balance, leaving out collateral and unsettled credits
+	CashOnly bool
+}
+
+// SizingResult is the size of a trade and the constraint that bound it
+type SizingResult struct {
+	Quantity    int32
+	Lots        int32
+	Constraint  string
+	Explanation string
+
+	LotSize         int32
+	RiskPerLot      float64
+	MarginPerLot    float64
+	Balance         float64
+	MaxLotsByRisk   int32
+	MaxLotsByMargin int32
+}
+
+// SizePosition computes the largest number of lots that keeps the loss at
+// the stop loss within RiskBudget and the margin within the balance. The
+// margin of one lot comes from CalculateMargins and the balance from
+// GetFundLimit.
+func (c *Client) SizePosition(ctx context.Context, req SizingRequest) (SizingResult, error) {
+	if req.Price <= 0 {
+		return SizingResult{}, errors.New("dhanhq: sizing needs a positive price")
+	}
+	if req.RiskBudget <= 0 {
+		return SizingResult{}, errors.New("dhanhq: sizing needs a positive risk budget")
+	}
+	lotSize := req.Instrument.LotSize
+	if lotSize < 1 {
+		lotSize = 1
+	}
+	var riskPerUnit float64
+	switch req.TransactionType {
+	case TransactionTypeBuy:
+		riskPerUnit = req.Price - req.StopLoss
+	case TransactionTypeSell:
+		riskPerUnit = req.StopLoss - req.Price
+	default:
+		return SizingResult{}, fmt.Errorf("dhanhq: sizing needs a BUY or SELL transaction type, got %q", req.TransactionType)
+	}
+	if riskPerUnit <= 0 {
+		return SizingResult{}, fmt.Errorf("dhanhq: the stop loss of a %s must be on the losing side of the price", req.TransactionType)
+	}
+	riskPerLot := riskPerUnit * float64(lotSize)
+
+	margin, err := c.CalculateMarginsContext(ctx, Margin{
+		DhanClientId:    c.snapshot().dhanClientId,
+		ExchangeSegment: req.Instrument.ExchangeSegment,
+		TransactionType: req.TransactionType,
+		Quantity:        lotSize,
+		ProductType:     req.ProductType,
+		SecurityId:      req.Instrument.SecurityId,
+		Price:           req.Price,
+	})
+	if err != nil {
+		return SizingResult{}, err
+	}
+	marginPerLot := margin.TotalMargin
+	if marginPerLot <= 0 && margin.Leverage > 0 {
+		marginPerLot = req.Price * float64(lotSize) / margin.Leverage
+	}
+	if marginPerLot <= 0 {
+		return SizingResult{}, fmt.Errorf("dhanhq: no margin returned for %s:%s", req.Instrument.ExchangeSegment, req.Instrument.SecurityId)
+	}
+
+	fundLimit, err := c.GetFundLimitContext(ctx)
+	if err != nil {
+		return SizingResult{}, err
+	}
+	balance := fundLimit.AvailabelBalance
+	if req.CashOnly && fundLimit.WithdrawableBalance < balance {
+		balance = fundLimit.WithdrawableBalance
+	}
+	balance = math.Max(balance, 0)
+
+	result := SizingResult{
+		LotSize:         lotSize,
+		RiskPerLot:      riskPerLot,
+		MarginPerLot:    marginPerLot,
+		Balance:         balance,
+		MaxLotsByRisk:   int32(math.Floor(req.RiskBudget / riskPerLot)),
+		MaxLotsByMargin: int32(math.Floor(balance / marginPerLot)),
+	}
+
+	if result.MaxLotsByRisk <= result.MaxLotsByMargin {
+		result.Lots = result.MaxLotsByRisk
+		result.Constraint = SizingConstraintRisk
+		result.Explanation = fmt.Sprintf("risk budget %.2f allows %d lots at %.2f risk per lot", req.RiskBudget, result.MaxLotsByRisk, riskPerLot)
+	} else {
+		result.Lots = result.MaxLotsByMargin
+		result.Constraint = SizingConstraintMargin
+		result.Explanation = fmt.Sprintf("balance %.2f allows %d lots at %.2f margin per lot", balance, result.MaxLotsByMargin, marginPerLot)
+	}
+	if req.MaxLots > 0 && req.MaxLots < result.Lots {
+		result.Lots = req.MaxLots
+		result.Constraint = SizingConstraintMaxLots
+		result.Explanation = fmt.Sprintf("capped at %d lots", req.MaxLots)
+	}
+	result.Quantity = result.Lots * lotSize
+	return result, nil
+}
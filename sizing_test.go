@@ -0,0 +1,148 @@
+package dhanhq
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// sizingBroker serves the margin calculator and the fund limit
+type sizingBroker struct {
+	margin         MarginResponse
+	available      float64
+	withdrawable   float64
+	fundLimitFails bool
+}
+
+func (b *sizingBroker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case URIMarginCalculator:
+		json.NewEncoder(w).Encode(b.margin)
+	case URIFundLimit:
+		if b.fundLimitFails {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"errorType":"Internal_Server_Error","errorCode":"DH-908","errorMessage":"Internal server error"}`))
+			return
+		}
+		fmt.Fprintf(w, `{"availabelBalance":%g,"withdrawableBalance":%g}`, b.available, b.withdrawable)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestSizePosition(t *testing.T) {
+	nifty := Instrument{ExchangeSegment: ExchangeSegmentFNONSE, SecurityId: "35001", LotSize: 75, TickSize: 0.05}
+	buy := SizingRequest{
+		Instrument:      nifty,
+		TransactionType: TransactionTypeBuy,
+		ProductType:     ProductTypeIntraday,
+		Price:           100,
+		StopLoss:        98,
+		RiskBudget:      3000,
+	}
+	with := func(fn func(req *SizingRequest)) SizingRequest {
+		req := buy
+		fn(&req)
+		return req
+	}
+	// 75 units at 2 points of risk is 150 per lot, 3000 of budget allows 20 lots
+	tests := []struct {
+		name       string
+		broker     sizingBroker
+		req        SizingRequest
+		lots       int32
+		constraint string
+	}{
+		{"risk binds", sizingBroker{margin: MarginResponse{TotalMargin: 2000}, available: 100000}, buy, 20, SizingConstraintRisk},
+		{"margin binds", sizingBroker{margin: MarginResponse{TotalMargin: 2000}, available: 21999}, buy, 10, SizingConstraintMargin},
+		{"equal limits prefer risk", sizingBroker{margin: MarginResponse{TotalMargin: 2000}, available: 40000}, buy, 20, SizingConstraintRisk},
+		{"max lots binds", sizingBroker{margin: MarginResponse{TotalMargin: 2000}, available: 100000},
+			with(func(r *SizingRequest) { r.MaxLots = 5 }), 5, SizingConstraintMaxLots},
+		{"max lots above the limits", sizingBroker{margin: MarginResponse{TotalMargin: 2000}, available: 100000},
+			with(func(r *SizingRequest) { r.MaxLots = 50 }), 20, SizingConstraintRisk},
+		{"cash only", sizingBroker{margin: MarginResponse{TotalMargin: 2000}, available: 100000, withdrawable: 10000},
+			with(func(r *SizingRequest) { r.CashOnly = true }), 5, SizingConstraintMargin},
+		{"cash only with more cash than available", sizingBroker{margin: MarginResponse{TotalMargin: 2000}, available: 10000, withdrawable: 100000},
+			with(func(r *SizingRequest) { r.CashOnly = true }), 5, SizingConstraintMargin},
+		{"negative balance", sizingBroker{margin: MarginResponse{TotalMargin: 2000}, available: -500}, buy, 0, SizingConstraintMargin},
+		{"margin from leverage", sizingBroker{margin: MarginResponse{Leverage: 5}, available: 15000}, buy, 10, SizingConstraintMargin},
+		{"sell", sizingBroker{margin: MarginResponse{TotalMargin: 2000}, available: 100000},
+			with(func(r *SizingRequest) { r.TransactionType, r.StopLoss = TransactionTypeSell, 104 }), 10, SizingConstraintRisk},
+		{"lot size of zero", sizingBroker{margin: MarginResponse{TotalMargin: 50}, available: 100000},
+			with(func(r *SizingRequest) { r.Instrument.LotSize = 0 }), 1500, SizingConstraintRisk},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			srv := httptest.NewServer(&test.broker)
+			defer srv.Close()
+			client := NewClient(WithBaseURI(srv.URL), WithCredentials("client", "token"))
+
+			result, err := client.SizePosition(context.Background(), test.req)
+			if err != nil {
+				t.Fatalf("SizePosition: %v", err)
+			}
+			if result.Lots != test.lots || result.Constraint != test.constraint {
+				t.Errorf("got %d lots bound by %s, want %d by %s (%s)", result.Lots, result.Constraint, test.lots, test.constraint, result.Explanation)
+			}
+			if result.Quantity != result.Lots*result.LotSize {
+				t.Errorf("quantity %d is not %d lots of %d", result.Quantity, result.Lots, result.LotSize)
+			}
+		})
+	}
+}
+
+func TestSizePositionErrors(t *testing.T) {
+	valid := SizingRequest{
+		Instrument:      Instrument{ExchangeSegment: ExchangeSegmentEquityNSE, SecurityId: "1333", LotSize: 1},
+		TransactionType: TransactionTypeBuy,
+		ProductType:     ProductTypeIntraday,
+		Price:           1650,
+		StopLoss:        1630,
+		RiskBudget:      2000,
+	}
+	with := func(fn func(req *SizingRequest)) SizingRequest {
+		req := valid
+		fn(&req)
+		return req
+	}
+	tests := []struct {
+		name   string
+		broker sizingBroker
+		req    SizingRequest
+		want   string
+	}{
+		{"buy stop above the price", sizingBroker{}, with(func(r *SizingRequest) { r.StopLoss = 1670 }), "losing side"},
+		{"sell stop below the price", sizingBroker{}, with(func(r *SizingRequest) { r.TransactionType = TransactionTypeSell }), "losing side"},
+		{"stop at the price", sizingBroker{}, with(func(r *SizingRequest) { r.StopLoss = 1650 }), "losing side"},
+		{"no price", sizingBroker{}, with(func(r *SizingRequest) { r.Price = 0 }), "positive price"},
+		{"no risk budget", sizingBroker{}, with(func(r *SizingRequest) { r.RiskBudget = 0 }), "positive risk budget"},
+		{"no transaction type", sizingBroker{}, with(func(r *SizingRequest) { r.TransactionType = "" }), "BUY or SELL"},
+		{"no margin", sizingBroker{available: 100000}, valid, "no margin returned"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			srv := httptest.NewServer(&test.broker)
+			defer srv.Close()
+			client := NewClient(WithBaseURI(srv.URL), WithCredentials("client", "token"))
+
+			result, err := client.SizePosition(context.Background(), test.req)
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Errorf("got %+v, %v, want an error containing %q", result, err, test.want)
+			}
+		})
+	}
+
+	// A failed fetch of the fund limit is the API error, not a size of 0 lots
+	srv := httptest.NewServer(&sizingBroker{margin: MarginResponse{TotalMargin: 330}, fundLimitFails: true})
+	defer srv.Close()
+	_, err := NewClient(WithBaseURI(srv.URL), WithCredentials("client", "token")).SizePosition(context.Background(), valid)
+	var errResp ErrorResponse
+	if !errors.As(err, &errResp) || errResp.ErrorCode != "DH-908" {
+		t.Errorf("got %v, want the DH-908 error", err)
+	}
+}
@@ -0,0 +1,188 @@
+package dhanhq
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// statementDateFormat is the date format of the statement endpoints
+const statementDateFormat = "2006-01-02"
+
+// statementDate formats the IST date of t, the statement endpoints take dates in IST
+func statementDate(t time.Time) string {
+	return t.In(istLocation).Format(statementDateFormat)
+}
+
+// maxTradeHistoryPages bounds the pages GetTradeHistory fetches, in case the
+// API never returns an empty page
+const maxTradeHistoryPages = 10000
+
+// LedgerEntry is a credit or a debit on the trading account
+type LedgerEntry struct {
+	DhanClientId  string  `json:"dhanClientId"`
+	Narration     string  `json:"narration"`
+	VoucherDate   string  `json:"voucherdate"`
+	Exchange      string  `json:"exchange"`
+	VoucherDesc   string  `json:"voucherdesc"`
+	VoucherNumber string  `json:"vouchernumber"`
+	Debit         float64 `json:"debit"`
+	Credit        float64 `json:"credit"`
+	RunningBal    float64 `json:"runbal"`
+}
+
+// UnmarshalJSON decodes the amounts, which DhanHQ sends as strings
+func (e *LedgerEntry) UnmarshalJSON(data []byte) error {
+	type entry LedgerEntry
+	aux := struct {
+		*entry
+		Debit      marginAmount `json:"debit"`
+		Credit     marginAmount `json:"credit"`
+		RunningBal marginAmount `json:"runbal"`
+	}{entry: (*entry)(e)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	e.Debit = float64(aux.Debit)
+	e.Credit = float64(aux.Credit)
+	e.RunningBal = float64(aux.RunningBal)
+	return nil
+}
+
+// Trade is an executed trade from the trade history, with its charges
+type Trade struct {
+	DhanClientId               string  `json:"dhanClientId"`
+	OrderId                    string  `json:"orderId"`
+	ExchangeOrderId            string  `json:"exchangeOrderId"`
+	ExchangeTradeId            string  `json:"exchangeTradeId"`
+	TransactionType            string  `json:"transactionType"`
+	ExchangeSegment            string  `json:"exchangeSegment"`
+	ProductType                string  `json:"productType"`
+	OrderType                  string  `json:"orderType"`
+	TradingSymbol              string  `json:"tradingSymbol"`
+	CustomSymbol               string  `json:"customSymbol"`
+	SecurityId                 string  `json:"securityId"`
+	TradedQuantity             int32   `json:"tradedQuantity"`
+	TradedPrice                float64 `json:"tradedPrice"`
+	ISIN                       string  `json:"isin"`
+	Instrument                 string  `json:"instrument"`
+	SebiTax                    float64 `json:"sebiTax"`
+	STT                        float64 `json:"stt"`
+	BrokerageCharges           float64 `json:"brokerageCharges"`
+	ServiceTax                 float64 `json:"serviceTax"`
+	ExchangeTransactionCharges float64 `json:"exchangeTransactionCharges"`
+	StampDuty                  float64 `json:"stampDuty"`
+	CreateTime                 string  `json:"createTime"`
+	UpdateTime                 string  `json:"updateTime"`
+	ExchangeTime               string  `json:"exchangeTime"`
+	DrvExpiryDate              string  `json:"drvExpiryDate"`
+	DrvOptionType              string  `json:"drvOptionType"`
+	DrvStrikePrice             float64 `json:"drvStrikePrice"`
+}
+
+// Charges returns the taxes and charges paid on the trade
+func (t Trade) Charges() float64 {
+	return t.SebiTax + t.STT + t.BrokerageCharges + t.ServiceTax + t.ExchangeTransactionCharges + t.StampDuty
+}
+
+// GetLedger retrieves the ledger entries between two dates, both included
+// and taken in IST
+func (c *Client) GetLedger(from, to time.Time) ([]LedgerEntry, error) {
+	return c.GetLedgerContext(context.Background(), from, to)
+}
+
+// GetLedgerContext is GetLedger with a context for cancellation and tracing
+func (c *Client) GetLedgerContext(ctx context.Context, from, to time.Time) (_ []LedgerEntry, err error) {
+	ctx, span := c.startSpan(ctx, "GetLedger")
+	defer func() { endSpan(span, err) }()
+
+	cfg, err := c.authorize(ctx)
+	if err != nil {
+		return nil, err
+	}
+	headers := http.Header{
+		"Accept":       {"application/json"},
+		"access-token": {cfg.accessToken},
+	}
+	params := url.Values{
+		"from-date": {statementDate(from)},
+		"to-date":   {statementDate(to)},
+	}
+	resp, err := cfg.httpClient.DoContext(ctx, http.MethodGet, cfg.baseURI+URILedger+"?"+params.Encode(), headers, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkResponse(resp); err != nil {
+		return nil, err
+	}
+
+	var entries []LedgerEntry
+	if err := json.Unmarshal(resp.Body, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// GetTradeHistory retrieves the trades between two dates, both included
+// and taken in IST, fetching every page of the history
+func (c *Client) GetTradeHistory(from, to time.Time) ([]Trade, error) {
+	return c.GetTradeHistoryContext(context.Background(), from, to)
+}
+
+// GetTradeHistoryContext is GetTradeHistory with a context for cancellation and tracing
+func (c *Client) GetTradeHistoryContext(ctx context.Context, from, to time.Time) (_ []Trade, err error) {
+	ctx, span := c.startSpan(ctx, "GetTradeHistory")
+	defer func() { endSpan(span, err) }()
+
+	var trades []Trade
+	for page := 0; page < maxTradeHistoryPages; page++ {
+		pageTrades, err := c.GetTradeHistoryPageContext(ctx, from, to, page)
+		if err != nil {
+			return nil, err
+		}
+		if len(pageTrades) == 0 {
+			return trades, nil
+		}
+		trades = append(trades, pageTrades...)
+	}
+	return nil, fmt.Errorf("dhanhq: trade history has more than %d pages", maxTradeHistoryPages)
+}
+
+// GetTradeHistoryPage retrieves one page of the trade history between two
+// dates taken in IST, pages start at 0 and the first empty page ends the history
+func (c *Client) GetTradeHistoryPage(from, to time.Time, page int) ([]Trade, error) {
+	return c.GetTradeHistoryPageContext(context.Background(), from, to, page)
+}
+
+// GetTradeHistoryPageContext is GetTradeHistoryPage with a context for cancellation and tracing
+func (c *Client) GetTradeHistoryPageContext(ctx context.Context, from, to time.Time, page int) (_ []Trade, err error) {
+	ctx, span := c.startSpan(ctx, "GetTradeHistoryPage")
+	defer func() { endSpan(span, err) }()
+
+	cfg, err := c.authorize(ctx)
+	if err != nil {
+		return nil, err
+	}
+	headers := http.Header{
+		"Accept":       {"application/json"},
+		"access-token": {cfg.accessToken},
+	}
+	rURL := cfg.baseURI + fmt.Sprintf(URITradeHistory, statementDate(from), statementDate(to), strconv.Itoa(page))
+	resp, err := cfg.httpClient.DoContext(ctx, http.MethodGet, rURL, headers, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkResponse(resp); err != nil {
+		return nil, err
+	}
+
+	var trades []Trade
+	if err := json.Unmarshal(resp.Body, &trades); err != nil {
+		return nil, err
+	}
+	return trades, nil
+}
@@ -0,0 +1,51 @@
+package dhanhq
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTradeHistoryDatesInIST(t *testing.T) {
+	var mu sync.Mutex
+	var paths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		mu.Unlock()
+		switch r.URL.Path {
+		case "/trades/2024-05-01/2024-05-31/0", "/trades/2024-05-01/2024-05-31/1":
+			w.Write([]byte(`[{"orderId":"1","tradingSymbol":"HDFCBANK","tradedQuantity":10,"tradedPrice":1650}]`))
+		default:
+			w.Write([]byte(`[]`))
+		}
+	}))
+	defer srv.Close()
+	client := NewClient(WithBaseURI(srv.URL), WithCredentials("client", "token"))
+
+	// 20:00 UTC on April 30 is 01:30 on May 1 in IST
+	from := time.Date(2024, time.April, 30, 20, 0, 0, 0, time.UTC)
+	to := time.Date(2024, time.May, 31, 12, 0, 0, 0, time.UTC)
+
+	trades, err := client.GetTradeHistoryPage(from, to, 1)
+	if err != nil || len(trades) != 1 {
+		t.Fatalf("GetTradeHistoryPage = %v, %v", trades, err)
+	}
+	trades, err = client.GetTradeHistory(from, to)
+	if err != nil || len(trades) != 2 {
+		t.Fatalf("GetTradeHistory = %v, %v, want the trades of two pages", trades, err)
+	}
+
+	want := []string{
+		"/trades/2024-05-01/2024-05-31/1",
+		"/trades/2024-05-01/2024-05-31/0",
+		"/trades/2024-05-01/2024-05-31/1",
+		"/trades/2024-05-01/2024-05-31/2",
+	}
+	if !slices.Equal(paths, want) {
+		t.Errorf("requested %v, want %v", paths, want)
+	}
+}
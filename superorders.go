@@ -0,0 +1,190 @@
+package dhanhq
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// SuperOrderRequest places an entry order together with a target and a
+// stop loss order, the stop loss trails the price by TrailingJump if set
+type SuperOrderRequest struct {
+	DhanClientId    string  `json:"dhanClientId"`
+	CorrelationId   string  `json:"correlationId,omitempty"`
+	TransactionType string  `json:"transactionType"`
+	ExchangeSegment string  `json:"exchangeSegment"`
+	ProductType     string  `json:"productType"`
+	OrderType       string  `json:"orderType"`
+	SecurityId      string  `json:"securityId"`
+	Quantity        int32   `json:"quantity"`
+	Price           float64 `json:"price"`
+	TargetPrice     float64 `json:"targetPrice"`
+	StopLossPrice   float64 `json:"stopLossPrice"`
+	TrailingJump    float64 `json:"trailingJump"`
+}
+
+// ModifySuperOrderRequest modifies one leg of a super order. While the
+// entry leg is pending every field can be modified with LegNameEntry,
+// afterwards only TargetPrice can be modified with LegNameTarget and
+// StopLossPrice and TrailingJump with LegNameStopLoss. TrailingJump is left
+// unchanged when nil, a pointer to 0 turns off trailing.
+type ModifySuperOrderRequest struct {
+	DhanClientId  string   `json:"dhanClientId"`
+	OrderId       string   `json:"orderId"`
+	OrderType     string   `json:"orderType,omitempty"`
+	LegName       string   `json:"legName"`
+	Quantity      int32    `json:"quantity,omitempty"`
+	Price         float64  `json:"price,omitempty"`
+	TargetPrice   float64  `json:"targetPrice,omitempty"`
+	StopLossPrice float64  `json:"stopLossPrice,omitempty"`
+	TrailingJump  *float64 `json:"trailingJump,omitempty"`
+}
+
+// SuperOrderLeg is the target or stop loss leg of a super order
+type SuperOrderLeg struct {
+	OrderId           string  `json:"orderId"`
+	LegName           string  `json:"legName"`
+	TransactionType   string  `json:"transactionType"`
+	TotalQuantity     int32   `json:"totalQuatity"` // typo in api response as seen at https://dhanhq.co/docs/v2/super-order/
+	RemainingQuantity int32   `json:"remainingQuantity"`
+	TriggeredQuantity int32   `json:"triggeredQuantity"`
+	Price             float64 `json:"price"`
+	OrderStatus       string  `json:"orderStatus"`
+	TrailingJump      float64 `json:"trailingJump"`
+}
+
+// SuperOrder is a super order as listed by GetSuperOrders, the fields
+// describe the entry leg and LegDetails the target and stop loss legs
+type SuperOrder struct {
+	DhanClientId        string          `json:"dhanClientId"`
+	OrderId             string          `json:"orderId"`
+	CorrelationId       string          `json:"correlationId"`
+	OrderStatus         string          `json:"orderStatus"`
+	TransactionType     string          `json:"transactionType"`
+	ExchangeSegment     string          `json:"exchangeSegment"`
+	ProductType         string          `json:"productType"`
+	OrderType           string          `json:"orderType"`
+	Validity            string          `json:"validity"`
+	TradingSymbol       string          `json:"tradingSymbol"`
+	SecurityId          string          `json:"securityId"`
+	Quantity            int32           `json:"quantity"`
+	RemainingQuantity   int32           `json:"remainingQuantity"`
+	Ltp                 float64         `json:"ltp"`
+	Price               float64         `json:"price"`
+	AfterMarketOrder    bool            `json:"afterMarketOrder"`
+	LegName             string          `json:"legName"`
+	ExchangeOrderId     string          `json:"exchangeOrderId"`
+	CreateTime          string          `json:"createTime"`
+	UpdateTime          string          `json:"updateTime"`
+	ExchangeTime        string          `json:"exchangeTime"`
+	OmsErrorDescription string          `json:"omsErrorDescription"`
+	AverageTradedPrice  float64         `json:"averageTradedPrice"`
+	FilledQty           int32           `json:"filledQty"`
+	LegDetails          []SuperOrderLeg `json:"legDetails"`
+}
+
+// Leg returns the leg with the given name, the entry leg is not part of LegDetails
+func (o SuperOrder) Leg(legName string) (SuperOrderLeg, bool) {
+	for _, leg := range o.LegDetails {
+		if leg.LegName == legName {
+			return leg, true
+		}
+	}
+	return SuperOrderLeg{}, false
+}
+
+// PlaceSuperOrder places an entry order with its target and stop loss legs
+func (c *Client) PlaceSuperOrder(req SuperOrderRequest) (OrderResponse, error) {
+	return c.PlaceSuperOrderContext(context.Background(), req)
+}
+
+// PlaceSuperOrderContext is PlaceSuperOrder with a context for cancellation and tracing
+func (c *Client) PlaceSuperOrderContext(ctx context.Context, req SuperOrderRequest) (_ OrderResponse, err error) {
+	ctx, span := c.startSpan(ctx, "PlaceSuperOrder",
+		Attribute{Key: AttributeSecurityIds, Value: []string{req.ExchangeSegment + ":" + req.SecurityId}})
+	defer func() { endSpan(span, err) }()
+
+	cfg, err := c.authorize(ctx)
+	if err != nil {
+		return OrderResponse{}, err
+	}
+	if req.DhanClientId == "" {
+		req.DhanClientId = cfg.dhanClientId
+	}
+	return c.sendOrder(ctx, cfg, http.MethodPost, cfg.baseURI+URISuperOrders, req)
+}
+
+// ModifySuperOrderLeg modifies one leg of a pending super order
+func (c *Client) ModifySuperOrderLeg(req ModifySuperOrderRequest) (OrderResponse, error) {
+	return c.ModifySuperOrderLegContext(context.Background(), req)
+}
+
+// ModifySuperOrderLegContext is ModifySuperOrderLeg with a context for cancellation and tracing
+func (c *Client) ModifySuperOrderLegContext(ctx context.Context, req ModifySuperOrderRequest) (_ OrderResponse, err error) {
+	ctx, span := c.startSpan(ctx, "ModifySuperOrderLeg")
+	defer func() { endSpan(span, err) }()
+
+	cfg, err := c.authorize(ctx)
+	if err != nil {
+		return OrderResponse{}, err
+	}
+	if req.DhanClientId == "" {
+		req.DhanClientId = cfg.dhanClientId
+	}
+	rURL := cfg.baseURI + fmt.Sprintf(URIModifySuperOrder, url.PathEscape(req.OrderId))
+	return c.sendOrder(ctx, cfg, http.MethodPut, rURL, req)
+}
+
+// CancelSuperOrderLeg cancels one leg of a super order, cancelling the
+// entry leg cancels the whole super order
+func (c *Client) CancelSuperOrderLeg(orderId, legName string) (OrderResponse, error) {
+	return c.CancelSuperOrderLegContext(context.Background(), orderId, legName)
+}
+
+// CancelSuperOrderLegContext is CancelSuperOrderLeg with a context for cancellation and tracing
+func (c *Client) CancelSuperOrderLegContext(ctx context.Context, orderId, legName string) (_ OrderResponse, err error) {
+	ctx, span := c.startSpan(ctx, "CancelSuperOrderLeg")
+	defer func() { endSpan(span, err) }()
+
+	cfg, err := c.authorize(ctx)
+	if err != nil {
+		return OrderResponse{}, err
+	}
+	rURL := cfg.baseURI + fmt.Sprintf(URICancelSuperOrderLeg, url.PathEscape(orderId), url.PathEscape(legName))
+	return c.sendOrder(ctx, cfg, http.MethodDelete, rURL, nil)
+}
+
+// GetSuperOrders retrieves the super orders of the day with the status of every leg
+func (c *Client) GetSuperOrders() ([]SuperOrder, error) {
+	return c.GetSuperOrdersContext(context.Background())
+}
+
+// GetSuperOrdersContext is GetSuperOrders with a context for cancellation and tracing
+func (c *Client) GetSuperOrdersContext(ctx context.Context) (_ []SuperOrder, err error) {
+	ctx, span := c.startSpan(ctx, "GetSuperOrders")
+	defer func() { endSpan(span, err) }()
+
+	cfg, err := c.authorize(ctx)
+	if err != nil {
+		return nil, err
+	}
+	headers := http.Header{
+		"Accept":       {"application/json"},
+		"access-token": {cfg.accessToken},
+	}
+	resp, err := cfg.httpClient.DoContext(ctx, http.MethodGet, cfg.baseURI+URISuperOrders, headers, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkResponse(resp); err != nil {
+		return nil, err
+	}
+
+	var superOrders []SuperOrder
+	if err := json.Unmarshal(resp.Body, &superOrders); err != nil {
+		return nil, err
+	}
+	return superOrders, nil
+}
@@ -0,0 +1,231 @@
+package dhanhq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrTokenExpired is matched by errors.Is for every *TokenExpiredError
+var ErrTokenExpired = errors.New("dhanhq: access token expired")
+
+// TokenExpiredError is returned when a request is attempted with an access
+// token whose expiry time has passed
+type TokenExpiredError struct {
+	ExpiredAt time.Time
+}
+
+func (e *TokenExpiredError) Error() string {
+	return fmt.Sprintf("dhanhq: access token expired at %s", e.ExpiredAt.Format(time.RFC3339))
+}
+
+func (e *TokenExpiredError) Is(target error) bool {
+	return target == ErrTokenExpired
+}
+
+// ExpiredTokenPolicy decides what happens when a request is attempted
+// with an expired access token
+type ExpiredTokenPolicy int
+
+const (
+	// ExpiredTokenFail fails the request with a *TokenExpiredError
+	ExpiredTokenFail ExpiredTokenPolicy = iota
+
+	// ExpiredTokenWait blocks the request until a new token is set
+	// or the request's context is done
+	ExpiredTokenWait
+)
+
+// istLocation is used for timestamps DhanHQ returns without a zone
+var istLocation = time.FixedZone("IST", 5*60*60+30*60)
+
+// expiryLayouts are the layouts DhanHQ has been seen to use for expiryTime
+var expiryLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.000",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+}
+
+// ParseExpiryTime parses the expiryTime returned by DhanHQ, such as
+// ConsumeConsentResponse.ExpiryTime, timestamps without a zone are in IST
+func ParseExpiryTime(expiryTime string) (time.Time, error) {
+	for _, layout := range expiryLayouts {
+		if t, err := time.ParseInLocation(layout, expiryTime, istLocation); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("dhanhq: unrecognised expiry time %q", expiryTime)
+}
+
+// TokenManager tracks the expiry of a Client's access token, fires
+// callbacks before it expires and holds requests back once it has.
+// Every Client has one, see Client.Tokens.
+type TokenManager struct {
+	mu        sync.Mutex
+	expiry    time.Time
+	policy    ExpiredTokenPolicy
+	renewed   chan struct{} // closed and replaced whenever the token changes
+	callbacks map[int]*expiryCallback
+	nextId    int
+}
+
+type expiryCallback struct {
+	before time.Duration
+	fn     func(expiry time.Time)
+	timer  *time.Timer
+}
+
+// Expiry returns the expiry time of the current token, the zero time
+// means the expiry is unknown and the token is assumed valid
+func (m *TokenManager) Expiry() time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.expiry
+}
+
+// TimeToExpiry returns how long the current token remains valid,
+// ok is false if the expiry is unknown
+func (m *TokenManager) TimeToExpiry() (d time.Duration, ok bool) {
+	expiry := m.Expiry()
+	if expiry.IsZero() {
+		return 0, false
+	}
+	return time.Until(expiry), true
+}
+
+// Expired reports whether the current token is known to have expired
+func (m *TokenManager) Expired() bool {
+	d, ok := m.TimeToExpiry()
+	return ok && d <= 0
+}
+
+// SetPolicy sets what happens to requests attempted with an expired token
+func (m *TokenManager) SetPolicy(policy ExpiredTokenPolicy) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.policy = policy
+}
+
+// OnExpiry registers fn to be called once per token when it is within
+// before of expiring, fn is called right away if that time has passed.
+// Callbacks are rescheduled whenever a new token is set, the returned
+// function unregisters fn.
+func (m *TokenManager) OnExpiry(before time.Duration, fn func(expiry time.Time)) (cancel func()) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.callbacks == nil {
+		m.callbacks = make(map[int]*expiryCallback)
+	}
+	id := m.nextId
+	m.nextId++
+	cb := &expiryCallback{before: before, fn: fn}
+	m.callbacks[id] = cb
+	m.schedule(cb)
+
+	return func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		if cb.timer != nil {
+			cb.timer.Stop()
+		}
+		delete(m.callbacks, id)
+	}
+}
+
+// renew records the expiry of a newly set token, reschedules the callbacks
+// and wakes up requests waiting for a new token
+func (m *TokenManager) renew(expiry time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.expiry = expiry
+	for _, cb := range m.callbacks {
+		m.schedule(cb)
+	}
+	if m.renewed != nil {
+		close(m.renewed)
+		m.renewed = nil
+	}
+}
+
+// schedule (re)arms the timer of cb for the current expiry, m.mu must be held
+func (m *TokenManager) schedule(cb *expiryCallback) {
+	if cb.timer != nil {
+		cb.timer.Stop()
+		cb.timer = nil
+	}
+	if m.expiry.IsZero() {
+		return
+	}
+	expiry := m.expiry
+	cb.timer = time.AfterFunc(time.Until(expiry.Add(-cb.before)), func() { cb.fn(expiry) })
+}
+
+// wait returns a channel closed when the next token is set
+func (m *TokenManager) wait() (<-chan struct{}, ExpiredTokenPolicy) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.renewed == nil {
+		m.renewed = make(chan struct{})
+	}
+	return m.renewed, m.policy
+}
+
+// Tokens returns the manager tracking the expiry of the client's access token
+func (c *Client) Tokens() *TokenManager {
+	return &c.tokens
+}
+
+// SetAccessTokenWithExpiry sets the access token along with its expiry time
+func (c *Client) SetAccessTokenWithExpiry(accessToken string, expiry time.Time) {
+	c.update(func(cfg *clientConfig) {
+		cfg.accessToken = accessToken
+		cfg.tokenExpiry = expiry
+	})
+	c.tokens.renew(expiry)
+}
+
+// SetConsumedConsent sets the client id, access token and token expiry
+// from the response of a consumed consent and saves them to the token store, if any
+func (c *Client) SetConsumedConsent(resp ConsumeConsentResponse) error {
+	var expiry time.Time
+	if resp.ExpiryTime != "" {
+		var err error
+		if expiry, err = ParseExpiryTime(resp.ExpiryTime); err != nil {
+			return err
+		}
+	}
+	c.setStoredToken(StoredToken{
+		DhanClientId: resp.DhanClientId,
+		AccessToken:  resp.AccessToken,
+		ExpiryTime:   expiry,
+	})
+	if c.snapshot().tokenStore != nil {
+		return c.SaveCredentials()
+	}
+	return nil
+}
+
+// authorize returns the configuration to use for an authenticated request,
+// failing or waiting according to the policy if the token has expired
+func (c *Client) authorize(ctx context.Context) (*clientConfig, error) {
+	for {
+		renewed, policy := c.tokens.wait()
+		cfg := c.snapshot()
+		if cfg.tokenExpiry.IsZero() || time.Now().Before(cfg.tokenExpiry) {
+			return cfg, nil
+		}
+		if policy != ExpiredTokenWait {
+			return nil, &TokenExpiredError{ExpiredAt: cfg.tokenExpiry}
+		}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%w: %w", &TokenExpiredError{ExpiredAt: cfg.tokenExpiry}, ctx.Err())
+		case <-renewed:
+		}
+	}
+}
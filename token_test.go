@@ -0,0 +1,145 @@
+package dhanhq
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParseExpiryTime(t *testing.T) {
+	want := time.Date(2025, 1, 2, 15, 4, 5, 0, istLocation)
+	for _, s := range []string{
+		"2025-01-02T15:04:05",
+		"2025-01-02T15:04:05.000",
+		"2025-01-02 15:04:05",
+		"2025-01-02T09:34:05Z",
+		"2025-01-02T15:04:05+05:30",
+	} {
+		if got, err := ParseExpiryTime(s); err != nil || !got.Equal(want) {
+			t.Errorf("ParseExpiryTime(%q) = %v, %v, want %v", s, got, err, want)
+		}
+	}
+	if _, err := ParseExpiryTime("02/01/2025"); err == nil {
+		t.Error("ParseExpiryTime(02/01/2025) did not fail")
+	}
+}
+
+func newTokenTest(t *testing.T, opts ...Option) (*Client, *atomic.Int64) {
+	t.Helper()
+	var requests atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if token := r.Header.Get("access-token"); token != "new" {
+			t.Errorf("request sent with access token %q", token)
+		}
+		w.Write([]byte(`[]`))
+	}))
+	t.Cleanup(srv.Close)
+	opts = append([]Option{WithBaseURI(srv.URL), WithCredentials("client", "old")}, opts...)
+	return NewClient(opts...), &requests
+}
+
+func TestExpiredTokenFails(t *testing.T) {
+	expiredAt := time.Now().Add(-time.Minute).Truncate(time.Second)
+	client, requests := newTokenTest(t, WithTokenExpiry(expiredAt))
+
+	_, err := client.GetPositions()
+	var expiredErr *TokenExpiredError
+	if !errors.As(err, &expiredErr) || !expiredErr.ExpiredAt.Equal(expiredAt) {
+		t.Fatalf("got %v, want a TokenExpiredError at %v", err, expiredAt)
+	}
+	if !errors.Is(err, ErrTokenExpired) {
+		t.Errorf("%v does not match ErrTokenExpired", err)
+	}
+	if requests.Load() != 0 {
+		t.Error("a request was sent with an expired token")
+	}
+
+	client.SetAccessTokenWithExpiry("new", time.Now().Add(time.Hour))
+	if _, err := client.GetPositions(); err != nil {
+		t.Errorf("GetPositions with a new token: %v", err)
+	}
+}
+
+func TestExpiredTokenWaitsForNewToken(t *testing.T) {
+	client, requests := newTokenTest(t, WithTokenExpiry(time.Now().Add(-time.Minute)),
+		WithExpiredTokenPolicy(ExpiredTokenWait))
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := client.GetPositions()
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		t.Fatalf("GetPositions returned %v before a new token was set", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+	client.SetAccessTokenWithExpiry("new", time.Now().Add(time.Hour))
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("GetPositions: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("GetPositions still waiting after a new token was set")
+	}
+	if requests.Load() != 1 {
+		t.Errorf("sent %d requests, want 1", requests.Load())
+	}
+}
+
+func TestExpiredTokenWaitStopsWhenContextIsDone(t *testing.T) {
+	client, requests := newTokenTest(t, WithTokenExpiry(time.Now().Add(-time.Minute)),
+		WithExpiredTokenPolicy(ExpiredTokenWait))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := client.GetPositionsContext(ctx)
+	if !errors.Is(err, ErrTokenExpired) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got %v, want ErrTokenExpired and context.DeadlineExceeded", err)
+	}
+	if requests.Load() != 0 {
+		t.Error("a request was sent with an expired token")
+	}
+}
+
+func TestOnExpiry(t *testing.T) {
+	client := NewClient(WithCredentials("client", "token"))
+	fired := make(chan time.Time, 2)
+	cancel := client.Tokens().OnExpiry(time.Hour, func(expiry time.Time) { fired <- expiry })
+	defer cancel()
+
+	// Within an hour of expiring, so the callback fires right away
+	expiry := time.Now().Add(30 * time.Minute)
+	client.SetAccessTokenWithExpiry("token", expiry)
+	select {
+	case got := <-fired:
+		if !got.Equal(expiry) {
+			t.Errorf("callback got expiry %v, want %v", got, expiry)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("callback did not fire")
+	}
+
+	// Rescheduled for the new token, which is far from expiring
+	client.SetAccessTokenWithExpiry("token", time.Now().Add(24*time.Hour))
+	select {
+	case got := <-fired:
+		t.Errorf("callback fired for %v, a day before the expiry", got)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	if d, ok := client.Tokens().TimeToExpiry(); !ok || d < 23*time.Hour {
+		t.Errorf("TimeToExpiry = %v, %v", d, ok)
+	}
+	if client.Tokens().Expired() {
+		t.Error("token reported as expired")
+	}
+}
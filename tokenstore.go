@@ -0,0 +1,296 @@
+package dhanhq
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ErrTokenNotFound is returned by a TokenStore with no token for an account
+var ErrTokenNotFound = errors.New("dhanhq: token not found")
+
+// StoredToken holds the credentials of one account in a TokenStore
+type StoredToken struct {
+	DhanClientId string    `json:"dhanClientId"`
+	AccessToken  string    `json:"accessToken"`
+	ExpiryTime   time.Time `json:"expiryTime"`
+}
+
+// TokenStore persists access tokens per Dhan client id
+type TokenStore interface {
+	// Load returns the token of an account or ErrTokenNotFound
+	Load(dhanClientId string) (StoredToken, error)
+
+	// Save stores the token, replacing any previous token of the account
+	Save(token StoredToken) error
+
+	// Delete removes the token of an account, deleting a missing token is not an error
+	Delete(dhanClientId string) error
+}
+
+// MemoryTokenStore is a TokenStore kept in memory, mostly useful in tests
+type MemoryTokenStore struct {
+	mu     sync.RWMutex
+	tokens map[string]StoredToken
+}
+
+// NewMemoryTokenStore creates an empty in-memory token store
+func NewMemoryTokenStore() *MemoryTokenStore {
+	return &MemoryTokenStore{tokens: make(map[string]StoredToken)}
+}
+
+func (s *MemoryTokenStore) Load(dhanClientId string) (StoredToken, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	token, ok := s.tokens[dhanClientId]
+	if !ok {
+		return StoredToken{}, ErrTokenNotFound
+	}
+	return token, nil
+}
+
+func (s *MemoryTokenStore) Save(token StoredToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokens[token.DhanClientId] = token
+	return nil
+}
+
+func (s *MemoryTokenStore) Delete(dhanClientId string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.tokens, dhanClientId)
+	return nil
+}
+
+// pbkdf2Iterations follows the OWASP recommendation for PBKDF2-HMAC-SHA256
+const pbkdf2Iterations = 600_000
+
+// FileTokenStore is a TokenStore keeping the tokens of all accounts in a
+// single file encrypted with AES-256-GCM, using a key derived from a
+// passphrase with PBKDF2. The file is only readable by its owner.
+type FileTokenStore struct {
+	mu         sync.Mutex
+	path       string
+	passphrase string
+
+	// salt and key cache the last derived key, deriving one is slow on purpose
+	salt []byte
+	key  []byte
+}
+
+// encryptedTokenFile is the on-disk format of a FileTokenStore
+type encryptedTokenFile struct {
+	Version    int    `json:"version"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// NewFileTokenStore creates a token store at path encrypted with passphrase,
+// the file is created on the first Save
+func NewFileTokenStore(path, passphrase string) *FileTokenStore {
+	return &FileTokenStore{path: path, passphrase: passphrase}
+}
+
+func (s *FileTokenStore) Load(dhanClientId string) (StoredToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tokens, err := s.read()
+	if err != nil {
+		return StoredToken{}, err
+	}
+	token, ok := tokens[dhanClientId]
+	if !ok {
+		return StoredToken{}, ErrTokenNotFound
+	}
+	return token, nil
+}
+
+func (s *FileTokenStore) Save(token StoredToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tokens, err := s.read()
+	if err != nil {
+		return err
+	}
+	tokens[token.DhanClientId] = token
+	return s.write(tokens)
+}
+
+func (s *FileTokenStore) Delete(dhanClientId string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tokens, err := s.read()
+	if err != nil {
+		return err
+	}
+	if _, ok := tokens[dhanClientId]; !ok {
+		return nil
+	}
+	delete(tokens, dhanClientId)
+	return s.write(tokens)
+}
+
+// read decrypts the file, a missing file is an empty store
+func (s *FileTokenStore) read() (map[string]StoredToken, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return make(map[string]StoredToken), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var file encryptedTokenFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("dhanhq: reading token store: %w", err)
+	}
+	if file.Version != 1 {
+		return nil, fmt.Errorf("dhanhq: unsupported token store version %d", file.Version)
+	}
+
+	aead, err := s.cipher(file.Salt)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := aead.Open(nil, file.Nonce, file.Ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("dhanhq: decrypting token store, wrong passphrase or corrupted file")
+	}
+
+	tokens := make(map[string]StoredToken)
+	if err := json.Unmarshal(plaintext, &tokens); err != nil {
+		return nil, fmt.Errorf("dhanhq: reading token store: %w", err)
+	}
+	return tokens, nil
+}
+
+// write encrypts tokens and atomically replaces the file
+func (s *FileTokenStore) write(tokens map[string]StoredToken) error {
+	plaintext, err := json.Marshal(tokens)
+	if err != nil {
+		return err
+	}
+
+	salt := s.salt
+	if salt == nil {
+		salt = make([]byte, 16)
+		if _, err := rand.Read(salt); err != nil {
+			return err
+		}
+	}
+	aead, err := s.cipher(salt)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(encryptedTokenFile{
+		Version:    1,
+		Salt:       salt,
+		Nonce:      nonce,
+		Ciphertext: aead.Seal(nil, nonce, plaintext, nil),
+	})
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".dhanhq-tokens-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := tmp.Chmod(0o600); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
+
+// cipher returns the AEAD for the key derived from the passphrase and salt
+func (s *FileTokenStore) cipher(salt []byte) (cipher.AEAD, error) {
+	if s.passphrase == "" {
+		return nil, errors.New("dhanhq: token store passphrase is empty")
+	}
+	if s.key == nil || string(s.salt) != string(salt) {
+		key, err := pbkdf2.Key(sha256.New, s.passphrase, salt, pbkdf2Iterations, 32)
+		if err != nil {
+			return nil, err
+		}
+		s.salt, s.key = salt, key
+	}
+	block, err := aes.NewCipher(s.key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// SetTokenStore sets the store used by LoadCredentials and SaveCredentials,
+// SetConsumedConsent saves the new credentials to it automatically
+func (c *Client) SetTokenStore(store TokenStore) {
+	c.update(func(cfg *clientConfig) { cfg.tokenStore = store })
+}
+
+// LoadCredentials loads the credentials of an account from the token store
+// and sets them on the client
+func (c *Client) LoadCredentials(dhanClientId string) error {
+	store := c.snapshot().tokenStore
+	if store == nil {
+		return errors.New("dhanhq: no token store set")
+	}
+	token, err := store.Load(dhanClientId)
+	if err != nil {
+		return err
+	}
+	c.setStoredToken(token)
+	return nil
+}
+
+// SaveCredentials saves the client's current credentials to the token store
+func (c *Client) SaveCredentials() error {
+	cfg := c.snapshot()
+	if cfg.tokenStore == nil {
+		return errors.New("dhanhq: no token store set")
+	}
+	if cfg.dhanClientId == "" {
+		return errors.New("dhanhq: dhan client id is required to save credentials")
+	}
+	return cfg.tokenStore.Save(StoredToken{
+		DhanClientId: cfg.dhanClientId,
+		AccessToken:  cfg.accessToken,
+		ExpiryTime:   cfg.tokenExpiry,
+	})
+}
+
+func (c *Client) setStoredToken(token StoredToken) {
+	c.update(func(cfg *clientConfig) {
+		cfg.dhanClientId = token.DhanClientId
+		cfg.accessToken = token.AccessToken
+		cfg.tokenExpiry = token.ExpiryTime
+	})
+	c.tokens.renew(token.ExpiryTime)
+}
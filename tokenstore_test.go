@@ -0,0 +1,137 @@
+package dhanhq
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFileTokenStoreRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tokens")
+	store := NewFileTokenStore(path, "secret")
+
+	if _, err := store.Load("1000000001"); !errors.Is(err, ErrTokenNotFound) {
+		t.Fatalf("Load from a missing file: got %v, want ErrTokenNotFound", err)
+	}
+
+	expiry := time.Date(2025, 1, 2, 15, 4, 5, 0, istLocation)
+	tokens := []StoredToken{
+		{DhanClientId: "1000000001", AccessToken: "first", ExpiryTime: expiry},
+		{DhanClientId: "1000000002", AccessToken: "second"},
+	}
+	for _, token := range tokens {
+		if err := store.Save(token); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode != 0o600 {
+		t.Errorf("file mode is %v, want 0600", mode)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "first") || strings.Contains(string(data), "1000000001") {
+		t.Error("file contains the tokens in plain text")
+	}
+
+	// A new store derives the key again from the salt in the file
+	reopened := NewFileTokenStore(path, "secret")
+	for _, want := range tokens {
+		got, err := reopened.Load(want.DhanClientId)
+		if err != nil || got.AccessToken != want.AccessToken || !got.ExpiryTime.Equal(want.ExpiryTime) {
+			t.Errorf("Load(%s) = %+v, %v, want %+v", want.DhanClientId, got, err, want)
+		}
+	}
+
+	if err := reopened.Delete("1000000001"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if err := reopened.Delete("1000000001"); err != nil {
+		t.Errorf("Delete of a missing token: %v", err)
+	}
+	if _, err := reopened.Load("1000000001"); !errors.Is(err, ErrTokenNotFound) {
+		t.Errorf("Load after Delete: got %v, want ErrTokenNotFound", err)
+	}
+	if _, err := reopened.Load("1000000002"); err != nil {
+		t.Errorf("Load of the other account after Delete: %v", err)
+	}
+}
+
+func TestFileTokenStoreErrors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tokens")
+	if err := NewFileTokenStore(path, "secret").Save(StoredToken{DhanClientId: "1000000001", AccessToken: "token"}); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewFileTokenStore(path, "wrong").Load("1000000001"); err == nil || !strings.Contains(err.Error(), "wrong passphrase") {
+		t.Errorf("Load with a wrong passphrase: got %v", err)
+	}
+	if err := NewFileTokenStore(path, "").Save(StoredToken{DhanClientId: "1000000001"}); err == nil {
+		t.Error("Save with an empty passphrase did not fail")
+	}
+
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"not json", "garbage", "reading token store"},
+		{"unknown version", strings.Replace(string(data), `"version":1`, `"version":2`, 1), "unsupported token store version 2"},
+		{"tampered ciphertext", strings.Replace(string(data), `"ciphertext":"`, `"ciphertext":"AAAA`, 1), "corrupted file"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			corrupt := filepath.Join(t.TempDir(), "tokens")
+			if err := os.WriteFile(corrupt, []byte(test.data), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			_, err := NewFileTokenStore(corrupt, "secret").Load("1000000001")
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Errorf("got %v, want an error containing %q", err, test.want)
+			}
+		})
+	}
+}
+
+func TestClientCredentialsFromTokenStore(t *testing.T) {
+	store := NewMemoryTokenStore()
+	expiry := time.Now().Add(time.Hour).Truncate(time.Second)
+	store.Save(StoredToken{DhanClientId: "1000000001", AccessToken: "stored", ExpiryTime: expiry})
+
+	client := NewClient(WithTokenStore(store, "1000000001"))
+	if cfg := client.snapshot(); cfg.dhanClientId != "1000000001" || cfg.accessToken != "stored" {
+		t.Errorf("client credentials are %s/%s, want the stored token", cfg.dhanClientId, cfg.accessToken)
+	}
+	if got := client.Tokens().Expiry(); !got.Equal(expiry) {
+		t.Errorf("token expiry is %v, want %v", got, expiry)
+	}
+
+	if err := client.SetConsumedConsent(ConsumeConsentResponse{
+		DhanClientId: "1000000001",
+		AccessToken:  "consumed",
+		ExpiryTime:   "2030-01-02T15:04:05",
+	}); err != nil {
+		t.Fatalf("SetConsumedConsent: %v", err)
+	}
+	token, err := store.Load("1000000001")
+	if err != nil || token.AccessToken != "consumed" || token.ExpiryTime.Year() != 2030 {
+		t.Errorf("stored token is %+v, %v, want the consumed consent", token, err)
+	}
+
+	if err := NewClient().LoadCredentials("1000000001"); err == nil {
+		t.Error("LoadCredentials without a token store did not fail")
+	}
+}
@@ -0,0 +1,103 @@
+package dhanhq
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+)
+
+// Tracer starts spans around Client methods and HTTP attempts. Its shape
+// mirrors OpenTelemetry's trace.Tracer so that an OpenTelemetry tracer can
+// be plugged in with a thin adapter, without the SDK depending on it.
+type Tracer interface {
+	// Start creates a span as a child of any span in ctx and returns
+	// a context carrying the new span
+	Start(ctx context.Context, spanName string) (context.Context, Span)
+}
+
+// Span is a single traced operation started by a Tracer
+type Span interface {
+	// SetAttributes sets attributes such as the endpoint or security ids on the span
+	SetAttributes(attrs ...Attribute)
+
+	// RecordError records an error and marks the span as failed
+	RecordError(err error)
+
+	// End completes the span
+	End()
+}
+
+// Attribute is a key value pair attached to a span, values are
+// string, int, int64, float64, bool or []string
+type Attribute struct {
+	Key   string
+	Value any
+}
+
+// Attribute keys set by the SDK on its spans
+const (
+	AttributeEndpoint    = "dhanhq.endpoint"
+	AttributeSecurityIds = "dhanhq.security_ids"
+	AttributeErrorCode   = "dhanhq.error_code"
+	AttributeHTTPMethod  = "http.request.method"
+	AttributeHTTPStatus  = "http.response.status_code"
+	AttributeURL         = "url.full"
+)
+
+// noopTracer is used when no Tracer has been set on the client
+type noopTracer struct{}
+
+type noopSpan struct{}
+
+func (noopTracer) Start(ctx context.Context, _ string) (context.Context, Span) {
+	return ctx, noopSpan{}
+}
+
+func (noopSpan) SetAttributes(...Attribute) {}
+func (noopSpan) RecordError(error)          {}
+func (noopSpan) End()                       {}
+
+// SetTracer sets the tracer used to create a span per Client method and
+// per HTTP attempt, pass nil to disable tracing
+func (c *Client) SetTracer(t Tracer) {
+	c.updateHTTPClient(func(h *httpClient) { h.tracer = t })
+}
+
+// startSpan starts the span for a Client method, the returned context
+// must be passed down to the HTTP client so attempts become child spans
+func (c *Client) startSpan(ctx context.Context, method string, attrs ...Attribute) (context.Context, Span) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, span := c.snapshot().httpClient.GetClient().getTracer().Start(ctx, "dhanhq."+method)
+	if len(attrs) > 0 {
+		span.SetAttributes(attrs...)
+	}
+	return ctx, span
+}
+
+// endSpan records err and its DhanHQ error code on the span, if any, and ends it
+func endSpan(span Span, err error) {
+	if err != nil {
+		var apiErr ErrorResponse
+		if errors.As(err, &apiErr) && apiErr.ErrorCode != "" {
+			span.SetAttributes(Attribute{Key: AttributeErrorCode, Value: apiErr.ErrorCode})
+		}
+		span.RecordError(err)
+	}
+	span.End()
+}
+
+// securityIdsAttribute builds the security ids attribute from a
+// MarketDataInput as "SEGMENT:ID" pairs
+func securityIdsAttribute(input MarketDataInput) Attribute {
+	var ids []string
+	for segment, securityIds := range input {
+		for _, id := range securityIds {
+			ids = append(ids, segment+":"+strconv.Itoa(id))
+		}
+	}
+	sort.Strings(ids)
+	return Attribute{Key: AttributeSecurityIds, Value: ids}
+}
@@ -0,0 +1,112 @@
+package dhanhq
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+)
+
+// recordingTracer keeps every span it starts, with the span it was started under
+type recordingTracer struct {
+	mu    sync.Mutex
+	spans []*recordedSpan
+}
+
+type recordedSpan struct {
+	name   string
+	parent *recordedSpan
+	attrs  map[string]any
+	errs   []error
+	ended  bool
+}
+
+type spanKey struct{}
+
+func (t *recordingTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	parent, _ := ctx.Value(spanKey{}).(*recordedSpan)
+	span := &recordedSpan{name: name, parent: parent, attrs: make(map[string]any)}
+	t.mu.Lock()
+	t.spans = append(t.spans, span)
+	t.mu.Unlock()
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+func (s *recordedSpan) SetAttributes(attrs ...Attribute) {
+	for _, attr := range attrs {
+		s.attrs[attr.Key] = attr.Value
+	}
+}
+
+func (s *recordedSpan) RecordError(err error) { s.errs = append(s.errs, err) }
+func (s *recordedSpan) End()                  { s.ended = true }
+
+func TestTracingSpans(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/orders/1" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"errorType":"Invalid_Authentication","errorCode":"DH-901","errorMessage":"Client ID or user generated access token is invalid or expired."}`))
+			return
+		}
+		w.Write([]byte(`{"data":{"NSE_EQ":{"1333":{"last_price":1650.5}}},"status":"success"}`))
+	}))
+	defer srv.Close()
+
+	tracer := &recordingTracer{}
+	client := NewClient(WithBaseURI(srv.URL), WithCredentials("client", "token"), WithTracer(tracer))
+
+	if _, err := client.GetLTPContext(context.Background(), MarketDataInput{ExchangeSegmentEquityNSE: {1333}}); err != nil {
+		t.Fatalf("GetLTP: %v", err)
+	}
+	if _, err := client.GetOrderById("1"); err == nil {
+		t.Fatal("GetOrderById did not fail on a 401")
+	}
+
+	if len(tracer.spans) != 4 {
+		t.Fatalf("got %d spans, want a method and an HTTP span per call", len(tracer.spans))
+	}
+	ltp, ltpAttempt, order, orderAttempt := tracer.spans[0], tracer.spans[1], tracer.spans[2], tracer.spans[3]
+
+	if ltp.name != "dhanhq.GetLTP" || ltpAttempt.name != "HTTP POST" || ltpAttempt.parent != ltp {
+		t.Errorf("GetLTP spans are %q and %q under %v", ltp.name, ltpAttempt.name, ltpAttempt.parent)
+	}
+	if ids, _ := ltp.attrs[AttributeSecurityIds].([]string); !slices.Equal(ids, []string{"NSE_EQ:1333"}) {
+		t.Errorf("security ids attribute is %v", ltp.attrs[AttributeSecurityIds])
+	}
+	if ltpAttempt.attrs[AttributeEndpoint] != URIMarketfeedLTP || ltpAttempt.attrs[AttributeHTTPStatus] != http.StatusOK {
+		t.Errorf("HTTP span attributes are %v", ltpAttempt.attrs)
+	}
+
+	if order.name != "dhanhq.GetOrderById" || orderAttempt.parent != order {
+		t.Errorf("GetOrderById spans are %q and %q", order.name, orderAttempt.name)
+	}
+	if order.attrs[AttributeErrorCode] != "DH-901" || len(order.errs) != 1 {
+		t.Errorf("failed method span has attributes %v and errors %v", order.attrs, order.errs)
+	}
+	if orderAttempt.attrs[AttributeErrorCode] != "DH-901" {
+		t.Errorf("failed HTTP span has attributes %v", orderAttempt.attrs)
+	}
+
+	for _, span := range tracer.spans {
+		if !span.ended {
+			t.Errorf("span %s was not ended", span.name)
+		}
+	}
+}
+
+func TestContextCancellation(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("a request was sent with a cancelled context")
+	}))
+	defer srv.Close()
+
+	client := NewClient(WithBaseURI(srv.URL), WithCredentials("client", "token"))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := client.GetPositionsContext(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v, want context.Canceled", err)
+	}
+}
@@ -1,7 +1,9 @@
 package dhanhq
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/url"
 )
@@ -11,6 +13,13 @@ type GenerateConsentResponse struct {
 	ConsentStatus string `json:"consentStatus"`
 }
 
+// GenerateAppConsentResponse is returned by the individual (API key) consent flow
+type GenerateAppConsentResponse struct {
+	ConsentAppId     string `json:"consentAppId"`
+	ConsentAppStatus string `json:"consentAppStatus"`
+	Status           string `json:"status"`
+}
+
 type ConsumeConsentResponse struct {
 	DhanClientId         string `json:"dhanClientId"`
 	DhanClientName       string `json:"dhanClientName"`
@@ -21,15 +30,24 @@ type ConsumeConsentResponse struct {
 }
 
 func (c *Client) GenerateConsent(partnerSecret string) (GenerateConsentResponse, error) {
+	return c.GenerateConsentContext(context.Background(), partnerSecret)
+}
+
+// GenerateConsentContext is GenerateConsent with a context for cancellation and tracing
+func (c *Client) GenerateConsentContext(ctx context.Context, partnerSecret string) (_ GenerateConsentResponse, err error) {
+	ctx, span := c.startSpan(ctx, "GenerateConsent")
+	defer func() { endSpan(span, err) }()
+
+	cfg := c.snapshot()
 	// This contains the logic to generate the consent from the partner_id and
 	// partner_secret using the DhanHQ API
 	// Add the partner_secret and partner_id to the headers
 	consentHeaders := http.Header{
 		"partner_secret": {partnerSecret},
-		"partner_id":     {c.partnerId},
+		"partner_id":     {cfg.partnerId},
 	}
 
-	resp, err := c.httpClient.Do(http.MethodGet, c.authURI+URIPartnerGenerateConsent, consentHeaders, nil)
+	resp, err := cfg.httpClient.DoContext(ctx, http.MethodGet, cfg.authURI+URIPartnerGenerateConsent, consentHeaders, nil)
 	if err != nil {
 		return GenerateConsentResponse{}, err
 	}
@@ -38,17 +56,29 @@ func (c *Client) GenerateConsent(partnerSecret string) (GenerateConsentResponse,
 	if err := json.Unmarshal(resp.Body, &consentResponse); err != nil {
 		return GenerateConsentResponse{}, err
 	}
+	if consentResponse.ConsentId == "" {
+		return GenerateConsentResponse{}, apiErrorFromBody(resp.Body)
+	}
 
 	return consentResponse, nil
 }
 
 func (c *Client) ConsumeConsent(tokenId string, partnerSecret string) (ConsumeConsentResponse, error) {
+	return c.ConsumeConsentContext(context.Background(), tokenId, partnerSecret)
+}
+
+// ConsumeConsentContext is ConsumeConsent with a context for cancellation and tracing
+func (c *Client) ConsumeConsentContext(ctx context.Context, tokenId string, partnerSecret string) (_ ConsumeConsentResponse, err error) {
+	ctx, span := c.startSpan(ctx, "ConsumeConsent")
+	defer func() { endSpan(span, err) }()
+
+	cfg := c.snapshot()
 	// This contains the logic to consume the consent and thus return
 	// valid accessToken and clientId into type ConsumeConsentResponse for the client
 
 	consumeHeaders := http.Header{
 		"partner_secret": {partnerSecret},
-		"partner_id":     {c.partnerId},
+		"partner_id":     {cfg.partnerId},
 	}
 
 	// Create the params for the request
@@ -58,7 +88,7 @@ func (c *Client) ConsumeConsent(tokenId string, partnerSecret string) (ConsumeCo
 	// Add the tokenId to the params
 	consumeParams["tokenId"] = []string{tokenId}
 
-	resp, err := c.httpClient.Do(http.MethodPost, c.authURI+URIPartnerConsumeConsent, consumeHeaders, consumeParams)
+	resp, err := cfg.httpClient.DoContext(ctx, http.MethodPost, cfg.authURI+URIPartnerConsumeConsent, consumeHeaders, consumeParams)
 	if err != nil {
 		return ConsumeConsentResponse{}, err
 	}
@@ -66,6 +96,9 @@ func (c *Client) ConsumeConsent(tokenId string, partnerSecret string) (ConsumeCo
 	if err := json.Unmarshal(resp.Body, &consumeResponse); err != nil {
 		return ConsumeConsentResponse{}, err
 	}
+	if consumeResponse.AccessToken == "" {
+		return ConsumeConsentResponse{}, apiErrorFromBody(resp.Body)
+	}
 
 	return consumeResponse, nil
 }
@@ -73,5 +106,93 @@ func (c *Client) ConsumeConsent(tokenId string, partnerSecret string) (ConsumeCo
 func (c *Client) GenerateConsentLoginURL(consentId string) string {
 	// This returns the consent login URL for the user to login and get the tokenId
 	// which is then used to consume the consent
-	return c.authURI + URIPartnerConsentLogin + "?consentId=" + consentId
+	return c.GetAuthURI() + URIPartnerConsentLogin + "?consentId=" + consentId
+}
+
+// The individual (API key) flow lets traders log in to their own account with
+// the app_id and app_secret generated on web.dhan.co, it mirrors the partner
+// flow: generate a consent, log in through the browser to get a tokenId and
+// consume it for a 24 hour access token.
+
+// GenerateAppConsent generates a consent for the individual flow, the Dhan
+// client id must be set on the client
+func (c *Client) GenerateAppConsent(appId, appSecret string) (GenerateAppConsentResponse, error) {
+	return c.GenerateAppConsentContext(context.Background(), appId, appSecret)
+}
+
+// GenerateAppConsentContext is GenerateAppConsent with a context for cancellation and tracing
+func (c *Client) GenerateAppConsentContext(ctx context.Context, appId, appSecret string) (_ GenerateAppConsentResponse, err error) {
+	ctx, span := c.startSpan(ctx, "GenerateAppConsent")
+	defer func() { endSpan(span, err) }()
+
+	cfg := c.snapshot()
+	if cfg.dhanClientId == "" {
+		return GenerateAppConsentResponse{}, fmt.Errorf("dhan client id is required to generate an app consent")
+	}
+
+	consentHeaders := http.Header{
+		"app_id":     {appId},
+		"app_secret": {appSecret},
+	}
+	consentParams := url.Values{
+		"client_id": {cfg.dhanClientId},
+	}
+
+	// The client id is sent as a query parameter even though this is a POST
+	resp, err := cfg.httpClient.DoJSONContext(ctx, http.MethodPost, cfg.authURI+URIAppGenerateConsent, consentParams, nil, consentHeaders, nil)
+	if err != nil {
+		return GenerateAppConsentResponse{}, err
+	}
+
+	var consentResponse GenerateAppConsentResponse
+	if err := json.Unmarshal(resp.Body, &consentResponse); err != nil {
+		return GenerateAppConsentResponse{}, err
+	}
+	if consentResponse.ConsentAppId == "" {
+		return GenerateAppConsentResponse{}, apiErrorFromBody(resp.Body)
+	}
+
+	return consentResponse, nil
+}
+
+// GenerateAppConsentLoginURL returns the URL where the user logs in to get the tokenId
+// which is then used to consume the app consent
+func (c *Client) GenerateAppConsentLoginURL(consentAppId string) string {
+	return c.GetAuthURI() + URIAppConsentLogin + "?consentAppId=" + url.QueryEscape(consentAppId)
+}
+
+// ConsumeAppConsent consumes the tokenId received after the browser login and
+// returns the access token, pass the response to SetConsumedConsent to use it
+func (c *Client) ConsumeAppConsent(tokenId, appId, appSecret string) (ConsumeConsentResponse, error) {
+	return c.ConsumeAppConsentContext(context.Background(), tokenId, appId, appSecret)
+}
+
+// ConsumeAppConsentContext is ConsumeAppConsent with a context for cancellation and tracing
+func (c *Client) ConsumeAppConsentContext(ctx context.Context, tokenId, appId, appSecret string) (_ ConsumeConsentResponse, err error) {
+	ctx, span := c.startSpan(ctx, "ConsumeAppConsent")
+	defer func() { endSpan(span, err) }()
+
+	cfg := c.snapshot()
+	consumeHeaders := http.Header{
+		"app_id":     {appId},
+		"app_secret": {appSecret},
+	}
+	consumeParams := url.Values{
+		"tokenId": {tokenId},
+	}
+
+	resp, err := cfg.httpClient.DoContext(ctx, http.MethodGet, cfg.authURI+URIAppConsumeConsent, consumeHeaders, consumeParams)
+	if err != nil {
+		return ConsumeConsentResponse{}, err
+	}
+
+	var consumeResponse ConsumeConsentResponse
+	if err := json.Unmarshal(resp.Body, &consumeResponse); err != nil {
+		return ConsumeConsentResponse{}, err
+	}
+	if consumeResponse.AccessToken == "" {
+		return ConsumeConsentResponse{}, apiErrorFromBody(resp.Body)
+	}
+
+	return consumeResponse, nil
 }
@@ -0,0 +1,191 @@
+package dhanhq
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Codes of the violations reported by an OrderValidator
+const (
+	ViolationUnknownInstrument = "UNKNOWN_INSTRUMENT"
+	ViolationQuantity          = "QUANTITY"
+	ViolationLotSize           = "LOT_SIZE"
+	ViolationFreezeQuantity    = "FREEZE_QUANTITY"
+	ViolationTickSize          = "TICK_SIZE"
+	ViolationCircuitLimit      = "CIRCUIT_LIMIT"
+	ViolationProductType       = "PRODUCT_TYPE"
+	ViolationPrice             = "PRICE"
+)
+
+// SegmentProductTypes lists the product types allowed in each exchange
+// segment, segments missing from it are not checked
+var SegmentProductTypes = map[string][]string{
+	ExchangeSegmentEquityNSE: {ProductTypeCNC, ProductTypeIntraday, ProductTypeMTF, ProductTypeCO, ProductTypeBO},
+	ExchangeSegmentEquityBSE: {ProductTypeCNC, ProductTypeIntraday, ProductTypeMTF, ProductTypeCO, ProductTypeBO},
+	ExchangeSegmentFNONSE:    {ProductTypeIntraday, ProductTypeMargin, ProductTypeCO, ProductTypeBO},
+	ExchangeSegmentFNOBSE:    {ProductTypeIntraday, ProductTypeMargin, ProductTypeCO, ProductTypeBO},
+	ExchangeSegmentCurNSE:    {ProductTypeIntraday, ProductTypeMargin},
+	ExchangeSegmentCurBSE:    {ProductTypeIntraday, ProductTypeMargin},
+	ExchangeSegmentMCXCOMM:   {ProductTypeIntraday, ProductTypeMargin},
+	ExchangeSegmentIndex:     {},
+}
+
+// Violation is a rule an order breaks
+type Violation struct {
+	Field   string
+	Code    string
+	Message string
+}
+
+func (v Violation) Error() string {
+	return v.Field + ": " + v.Message
+}
+
+// ValidationError is returned for an order or conversion that would be rejected, it
+// lists every violation found
+type ValidationError struct {
+	ExchangeSegment string
+	SecurityId      string
+	Violations      []Violation
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Violations))
+	for i, violation := range e.Violations {
+		messages[i] = violation.Error()
+	}
+	return fmt.Sprintf("dhanhq: invalid request for %s:%s: %s", e.ExchangeSegment, e.SecurityId, strings.Join(messages, "; "))
+}
+
+// Has reports whether the error has a violation with the given code
+func (e *ValidationError) Has(code string) bool {
+	for _, violation := range e.Violations {
+		if violation.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+// OrderValidator checks orders against the instrument master and, unless
+// SkipCircuitLimits is set, the circuit limits of the latest market depth
+// quote before they are sent
+type OrderValidator struct {
+	client *Client
+	master *InstrumentMaster
+
+	// SkipCircuitLimits skips fetching the market depth quote of every order
+	SkipCircuitLimits bool
+}
+
+// NewOrderValidator creates an order validator using master for the
+// instruments and the client for the market depth quotes
+func (c *Client) NewOrderValidator(master *InstrumentMaster) *OrderValidator {
+	return &OrderValidator{client: c, master: master}
+}
+
+// Validate checks an order, it returns a *ValidationError listing the
+// violations or the error of fetching the market depth quote, also when
+// the quote of the order's instrument is missing
+func (v *OrderValidator) Validate(ctx context.Context, req OrderRequest) error {
+	var quote *MarketDepthQuote
+	if !v.SkipCircuitLimits && req.Price > 0 {
+		securityId, err := strconv.Atoi(req.SecurityId)
+		if err == nil {
+			depth, err := v.client.GetMarketDepthContext(ctx, MarketDataInput{req.ExchangeSegment: {securityId}})
+			if err != nil {
+				return err
+			}
+			q, ok := depth.Data[req.ExchangeSegment][req.SecurityId]
+			if !ok {
+				return fmt.Errorf("dhanhq: no market depth quote for %s %s to check the circuit limits", req.ExchangeSegment, req.SecurityId)
+			}
+			quote = &q
+		}
+	}
+	if err := v.ValidateWithQuote(req, quote); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ValidateWithQuote checks an order against the instrument master and the
+// circuit limits of quote, which may be nil to skip them
+func (v *OrderValidator) ValidateWithQuote(req OrderRequest, quote *MarketDepthQuote) *ValidationError {
+	var violations []Violation
+	add := func(field, code, format string, args ...any) {
+		violations = append(violations, Violation{Field: field, Code: code, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if allowed, ok := SegmentProductTypes[req.ExchangeSegment]; ok && !slices.Contains(allowed, req.ProductType) {
+		add("productType", ViolationProductType, "%s is not allowed in %s", req.ProductType, req.ExchangeSegment)
+	}
+	if req.Quantity <= 0 {
+		add("quantity", ViolationQuantity, "must be positive")
+	}
+	if req.OrderType == OrderTypeLimit || req.OrderType == OrderTypeStopLoss {
+		if req.Price <= 0 {
+			add("price", ViolationPrice, "must be positive for %s orders", req.OrderType)
+		}
+	}
+	if req.OrderType == OrderTypeStopLoss || req.OrderType == OrderTypeStopLossMarket {
+		if req.TriggerPrice <= 0 {
+			add("triggerPrice", ViolationPrice, "must be positive for %s orders", req.OrderType)
+		}
+	}
+
+	instrument, ok := v.master.Lookup(req.ExchangeSegment, req.SecurityId)
+	if !ok {
+		add("securityId", ViolationUnknownInstrument, "not in the instrument master")
+	} else {
+		if instrument.LotSize > 1 && req.Quantity%instrument.LotSize != 0 {
+			add("quantity", ViolationLotSize, "%d is not a multiple of the lot size %d", req.Quantity, instrument.LotSize)
+		}
+		if instrument.FreezeQuantity > 0 && req.Quantity > instrument.FreezeQuantity {
+			add("quantity", ViolationFreezeQuantity, "%d is above the freeze quantity %d, slice the order", req.Quantity, instrument.FreezeQuantity)
+		}
+		if req.Price > 0 && !OnTick(req.Price, instrument.TickSize) {
+			add("price", ViolationTickSize, "%g is not a multiple of the tick size %g, the nearest is %g",
+				req.Price, instrument.TickSize, instrument.RoundPrice(req.Price))
+		}
+		if req.TriggerPrice > 0 && !OnTick(req.TriggerPrice, instrument.TickSize) {
+			add("triggerPrice", ViolationTickSize, "%g is not a multiple of the tick size %g, the nearest is %g",
+				req.TriggerPrice, instrument.TickSize, instrument.RoundPrice(req.TriggerPrice))
+		}
+	}
+
+	if quote != nil {
+		prices := []struct {
+			field string
+			price float64
+		}{{"price", req.Price}, {"triggerPrice", req.TriggerPrice}}
+		for _, p := range prices {
+			field, price := p.field, p.price
+			if price <= 0 {
+				continue
+			}
+			if quote.LowerCircuitLimit > 0 && price < quote.LowerCircuitLimit {
+				add(field, ViolationCircuitLimit, "%g is below the lower circuit limit %g", price, quote.LowerCircuitLimit)
+			}
+			if quote.UpperCircuitLimit > 0 && price > quote.UpperCircuitLimit {
+				add(field, ViolationCircuitLimit, "%g is above the upper circuit limit %g", price, quote.UpperCircuitLimit)
+			}
+		}
+	}
+
+	if len(violations) == 0 {
+		return nil
+	}
+	return &ValidationError{ExchangeSegment: req.ExchangeSegment, SecurityId: req.SecurityId, Violations: violations}
+}
+
+// PlaceOrder validates an order and places it if it is valid
+func (v *OrderValidator) PlaceOrder(ctx context.Context, req OrderRequest) (OrderResponse, error) {
+	if err := v.Validate(ctx, req); err != nil {
+		return OrderResponse{}, err
+	}
+	return v.client.PlaceOrderContext(ctx, req)
+}
@@ -0,0 +1,140 @@
+package dhanhq
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+var testMaster = NewInstrumentMaster(
+	Instrument{ExchangeSegment: ExchangeSegmentEquityNSE, SecurityId: "1333", TradingSymbol: "HDFCBANK", LotSize: 1, TickSize: 0.05},
+	Instrument{ExchangeSegment: ExchangeSegmentFNONSE, SecurityId: "35001", TradingSymbol: "NIFTY-FUT", LotSize: 75, TickSize: 0.05, FreezeQuantity: 1800},
+)
+
+func TestValidateWithQuote(t *testing.T) {
+	limit := OrderRequest{
+		TransactionType: TransactionTypeBuy,
+		ExchangeSegment: ExchangeSegmentFNONSE,
+		ProductType:     ProductTypeIntraday,
+		OrderType:       OrderTypeLimit,
+		SecurityId:      "35001",
+		Quantity:        150,
+		Price:           22000.05,
+	}
+	with := func(fn func(req *OrderRequest)) OrderRequest {
+		req := limit
+		fn(&req)
+		return req
+	}
+	quote := &MarketDepthQuote{LowerCircuitLimit: 19800, UpperCircuitLimit: 24200}
+
+	tests := []struct {
+		name  string
+		req   OrderRequest
+		quote *MarketDepthQuote
+		want  []string
+	}{
+		{"valid", limit, quote, nil},
+		{"valid at the freeze quantity", with(func(r *OrderRequest) { r.Quantity = 1800 }), quote, nil},
+		{"not a lot multiple", with(func(r *OrderRequest) { r.Quantity = 100 }), quote, []string{ViolationLotSize}},
+		{"above the freeze quantity", with(func(r *OrderRequest) { r.Quantity = 1875 }), quote, []string{ViolationFreezeQuantity}},
+		{"off tick", with(func(r *OrderRequest) { r.Price = 22000.07 }), quote, []string{ViolationTickSize}},
+		{"below the lower circuit", with(func(r *OrderRequest) { r.Price = 19000 }), quote, []string{ViolationCircuitLimit}},
+		{"above the upper circuit", with(func(r *OrderRequest) { r.Price = 25000 }), quote, []string{ViolationCircuitLimit}},
+		{"circuits skipped without a quote", with(func(r *OrderRequest) { r.Price = 25000 }), nil, nil},
+		{"trigger above the upper circuit", with(func(r *OrderRequest) {
+			r.OrderType, r.TriggerPrice = OrderTypeStopLoss, 24500
+		}), quote, []string{ViolationCircuitLimit}},
+		{"product not allowed", with(func(r *OrderRequest) { r.ProductType = ProductTypeCNC }), quote, []string{ViolationProductType}},
+		{"unknown instrument", with(func(r *OrderRequest) { r.SecurityId = "99999" }), quote, []string{ViolationUnknownInstrument}},
+		{"zero quantity", with(func(r *OrderRequest) { r.Quantity = 0 }), quote, []string{ViolationQuantity}},
+		{"limit without price", with(func(r *OrderRequest) { r.Price = 0 }), quote, []string{ViolationPrice}},
+		{"stop loss market without trigger", with(func(r *OrderRequest) {
+			r.OrderType, r.Price = OrderTypeStopLossMarket, 0
+		}), quote, []string{ViolationPrice}},
+		{"several violations", with(func(r *OrderRequest) {
+			r.Quantity, r.Price = 1900, 25000.02
+		}), quote, []string{ViolationLotSize, ViolationFreezeQuantity, ViolationTickSize, ViolationCircuitLimit}},
+	}
+	validator := NewClient().NewOrderValidator(testMaster)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := validator.ValidateWithQuote(test.req, test.quote)
+			var codes []string
+			if err != nil {
+				for _, violation := range err.Violations {
+					codes = append(codes, violation.Code)
+				}
+			}
+			if !slices.Equal(codes, test.want) {
+				t.Errorf("violations are %v, want %v (%v)", codes, test.want, err)
+			}
+		})
+	}
+}
+
+func TestValidateFetchesCircuitLimits(t *testing.T) {
+	var status atomic.Int32
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if code := int(status.Load()); code != http.StatusOK {
+			w.WriteHeader(code)
+			w.Write([]byte(`{"errorType":"Rate_Limit","errorCode":"DH-904","errorMessage":"Too many requests"}`))
+			return
+		}
+		w.Write([]byte(`{"status":"success","data":{"NSE_EQ":{"1333":{"last_price":1650,"lower_circuit_limit":1485,"upper_circuit_limit":1815}}}}`))
+	}))
+	defer srv.Close()
+
+	validator := NewClient(WithBaseURI(srv.URL), WithCredentials("client", "token")).NewOrderValidator(testMaster)
+	order := OrderRequest{
+		TransactionType: TransactionTypeBuy,
+		ExchangeSegment: ExchangeSegmentEquityNSE,
+		ProductType:     ProductTypeCNC,
+		OrderType:       OrderTypeLimit,
+		SecurityId:      "1333",
+		Quantity:        1,
+		Price:           1900,
+	}
+
+	status.Store(http.StatusOK)
+	var validationErr *ValidationError
+	if err := validator.Validate(context.Background(), order); !errors.As(err, &validationErr) || !validationErr.Has(ViolationCircuitLimit) {
+		t.Errorf("got %v, want a circuit limit violation", err)
+	}
+	order.Price = 1700
+	if err := validator.Validate(context.Background(), order); err != nil {
+		t.Errorf("Validate: %v", err)
+	}
+
+	// A failed quote must not pass the order unchecked
+	status.Store(http.StatusTooManyRequests)
+	var errResp ErrorResponse
+	if err := validator.Validate(context.Background(), order); !errors.As(err, &errResp) || errResp.ErrorCode != "DH-904" {
+		t.Errorf("got %v, want the DH-904 error", err)
+	}
+
+	status.Store(http.StatusOK)
+	order.SecurityId = "35001"
+	order.ExchangeSegment = ExchangeSegmentFNONSE
+	order.ProductType = ProductTypeIntraday
+	order.Quantity = 75
+	if err := validator.Validate(context.Background(), order); err == nil || !strings.Contains(err.Error(), "no market depth quote") {
+		t.Errorf("got %v, want an error for the missing quote", err)
+	}
+
+	requests.Store(0)
+	validator.SkipCircuitLimits = true
+	if err := validator.Validate(context.Background(), order); err != nil {
+		t.Errorf("Validate without circuit limits: %v", err)
+	}
+	if requests.Load() != 0 {
+		t.Error("market depth fetched with SkipCircuitLimits")
+	}
+}